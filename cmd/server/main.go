@@ -2,54 +2,166 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/admission"
+	"github.com/tarunm/pubsub-system/internal/audit"
 	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/cors"
 	"github.com/tarunm/pubsub-system/internal/handlers"
+	"github.com/tarunm/pubsub-system/internal/logging"
 	"github.com/tarunm/pubsub-system/internal/pubsub"
+	"github.com/tarunm/pubsub-system/internal/ratelimit"
+	"github.com/tarunm/pubsub-system/internal/tenant"
+	"github.com/tarunm/pubsub-system/internal/tlsconfig"
+	"github.com/tarunm/pubsub-system/internal/tracing"
 )
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Println("[INFO] Starting PubSub server...")
-
 	// Load configuration
-	cfg := config.LoadConfig()
-	log.Printf("[INFO] Configuration loaded: Port=%s, RingBuffer=%d, SubscriberQueue=%d",
-		cfg.Port, cfg.RingBufferSize, cfg.SubscriberQueue)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+
+	// Structured logging for the server and the standard library log package
+	if err := logging.Setup(cfg.LoggingOptions()); err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	slog.Info("Starting PubSub server",
+		"port", cfg.Port, "ring_buffer", cfg.RingBufferSize, "subscriber_queue", cfg.SubscriberQueue)
 
 	// Initialize authentication
 	validator := auth.NewAPIKeyValidator(cfg.APIKeys, cfg.AuthEnabled)
 	if cfg.AuthEnabled {
-		log.Printf("[INFO] Authentication enabled with %d API key(s)", len(cfg.APIKeys))
+		slog.Info("Authentication enabled", "api_keys", len(cfg.APIKeys))
+		if cfg.JWTEnabled() {
+			verifier, err := auth.NewJWTVerifier(cfg.JWTSecret, cfg.JWTJWKSFile, cfg.JWTIssuer, cfg.JWTAudience)
+			if err != nil {
+				fatal("Failed to initialize JWT verification", err)
+			}
+			validator.SetJWTVerifier(verifier)
+			slog.Info("JWT bearer token authentication enabled")
+		}
 	} else {
-		log.Println("[INFO] Authentication disabled")
+		slog.Info("Authentication disabled")
+	}
+
+	// Initialize managed API keys for the admin API
+	var keyStore *auth.KeyStore
+	if len(cfg.AdminAPIKeys) > 0 {
+		var err error
+		keyStore, err = auth.NewKeyStore(cfg.APIKeyStoreFile)
+		if err != nil {
+			fatal("Failed to load API key store", err)
+		}
+		validator.SetAdminKeys(cfg.AdminAPIKeys)
+		validator.SetKeyStore(keyStore)
+		slog.Info("Admin API enabled", "admin_keys", len(cfg.AdminAPIKeys), "key_store", cfg.APIKeyStoreFile)
 	}
 
 	// Initialize pub/sub engine with configuration
 	engine := pubsub.NewPubSubEngine(cfg)
 
+	// Trace messages from publisher to consumer
+	traceProvider, err := tracing.Open(cfg.TracingOptions())
+	if err != nil {
+		fatal("Failed to configure tracing", err)
+	}
+	engine.SetTracerProvider(traceProvider.TracerProvider())
+	if cfg.TracingExporter != "" {
+		slog.Info("Tracing enabled", "exporter", cfg.TracingExporter, "sample_ratio", cfg.TracingSampleRatio)
+	}
+
 	// Initialize handlers
 	wsHandler := handlers.NewWebSocketHandler(engine, cfg, validator)
 	restHandler := handlers.NewRESTHandler(engine)
+	restHandler.SetKeyStore(keyStore)
+
+	// Load topic access control policy
+	if cfg.ACLFile != "" {
+		acl, err := auth.LoadACL(cfg.ACLFile)
+		if err != nil {
+			fatal("Failed to load ACL policy", err)
+		}
+		wsHandler.SetACL(acl)
+		restHandler.SetACL(acl)
+		slog.Info("Topic ACL loaded", "file", cfg.ACLFile)
+	}
+
+	// Load tenants and their API keys
+	if cfg.TenantsFile != "" {
+		tenants, err := tenant.LoadRegistry(cfg.TenantsFile)
+		if err != nil {
+			fatal("Failed to load tenants", err)
+		}
+		for _, t := range tenants.Tenants() {
+			validator.AddTenantKeys(t.Name, t.APIKeys)
+		}
+		wsHandler.SetTenants(tenants)
+		restHandler.SetTenants(tenants)
+		slog.Info("Tenants loaded", "tenants", len(tenants.Tenants()), "file", cfg.TenantsFile)
+	}
+
+	// Configure publish and connection rate limits
+	limiter := ratelimit.New(cfg.RateLimits())
+	wsHandler.SetRateLimiter(limiter)
+	if cfg.RateLimits() != (ratelimit.Config{}) {
+		slog.Info("Rate limiting enabled")
+	}
+
+	// Restrict browser origins for WebSocket upgrades and REST calls
+	originPolicy, err := cors.NewPolicy(cfg.AllowedOrigins)
+	if err != nil {
+		fatal("Invalid ALLOWED_ORIGINS", err)
+	}
+	wsHandler.SetOriginPolicy(originPolicy)
+	if len(cfg.AllowedOrigins) > 0 {
+		slog.Info("Allowed origins configured", "origins", strings.Join(cfg.AllowedOrigins, ","))
+	}
+
+	// Configure connection and subscription caps
+	admissionController := admission.NewController(cfg.ConnectionLimits())
+	wsHandler.SetAdmission(admissionController)
+	restHandler.SetAdmission(admissionController)
+
+	// Record security and administrative events
+	auditLog, err := audit.Open(cfg.AuditOptions())
+	if err != nil {
+		fatal("Failed to open audit log", err)
+	}
+	validator.SetAuditLog(auditLog)
+	wsHandler.SetAuditLog(auditLog)
+	restHandler.SetAuditLog(auditLog)
+	if cfg.AuditLogFile != "" {
+		slog.Info("Audit log enabled", "file", cfg.AuditLogFile)
+	}
+
+	// Count authentication failures alongside the engine's metrics
+	validator.SetMetrics(engine.Metrics())
 
 	// Setup Gin router
 	gin.SetMode(cfg.GinMode)
 	router := gin.New()
-	router.Use(gin.Logger())
+	router.Use(logging.Middleware())
 	router.Use(gin.Recovery())
+	router.Use(cors.Middleware(originPolicy))
 
 	// Create auth middleware
 	authMiddleware := auth.AuthMiddleware(validator)
 
 	// Unprotected endpoints
 	router.GET("/health", restHandler.GetHealth)
+	router.GET("/livez", restHandler.Livez)
+	router.GET("/readyz", restHandler.Readyz)
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"service": "PubSub System",
@@ -58,7 +170,11 @@ func main() {
 				"websocket": "/ws",
 				"topics":    "/topics",
 				"health":    "/health",
+				"livez":     "/livez",
+				"readyz":    "/readyz",
+				"metrics":   "/metrics",
 				"stats":     "/stats",
+				"scheduled": "/scheduled",
 			},
 		})
 	})
@@ -74,6 +190,29 @@ func main() {
 		protected.DELETE("/topics/:name", restHandler.DeleteTopic)
 		protected.GET("/topics", restHandler.ListTopics)
 		protected.GET("/stats", restHandler.GetStats)
+		protected.GET("/scheduled", restHandler.ListScheduled)
+		protected.DELETE("/scheduled/:id", restHandler.CancelScheduled)
+		if cfg.MetricsEnabled {
+			protected.GET("/metrics", restHandler.GetMetrics)
+		}
+	}
+
+	// Admin API endpoints (admin keys only)
+	if keyStore != nil {
+		adminHandler := handlers.NewAdminHandler(engine, keyStore, cfg.GetWriteWait())
+		adminHandler.SetAuditLog(auditLog)
+		admin := router.Group("/admin")
+		admin.Use(auth.AdminMiddleware(validator))
+		{
+			admin.POST("/keys", adminHandler.CreateKey)
+			admin.GET("/keys", adminHandler.ListKeys)
+			admin.PATCH("/keys/:id", adminHandler.UpdateKey)
+			admin.DELETE("/keys/:id", adminHandler.RevokeKey)
+			admin.GET("/clients", adminHandler.ListClients)
+			admin.GET("/clients/:id", adminHandler.GetClient)
+			admin.DELETE("/clients/:id", adminHandler.DisconnectClient)
+			admin.DELETE("/clients/:id/subscriptions/:topic", adminHandler.UnsubscribeClient)
+		}
 	}
 
 	// HTTP server configuration with timeouts from config
@@ -85,14 +224,85 @@ func main() {
 		IdleTimeout:  cfg.IdleTimeout,
 	}
 
+	// Serve HTTPS/WSS when a certificate is configured
+	var certs *tlsconfig.Reloader
+	stopReload := make(chan struct{})
+	defer close(stopReload)
+	if cfg.TLSEnabled() {
+		certs, err = tlsconfig.NewReloader(cfg.TLSOptions())
+		if err != nil {
+			fatal("Failed to load TLS configuration", err)
+		}
+		srv.TLSConfig = certs.Config()
+		if cfg.TLSReloadInterval > 0 {
+			go certs.Watch(cfg.TLSReloadInterval, stopReload)
+		}
+		if cfg.TLSClientCAFile != "" {
+			slog.Info("TLS enabled with client certificate verification", "client_ca", cfg.TLSClientCAFile)
+		} else {
+			slog.Info("TLS enabled")
+		}
+	}
+
+	// Apply API keys, rate limits and the log level from the config file without a restart
+	var settings *config.Reloader
+	if cfg.ConfigFile != "" {
+		settings = config.NewReloader(cfg, func(next *config.Config) {
+			validator.SetAPIKeys(next.APIKeys)
+			limiter.Update(next.RateLimits())
+			if err := logging.SetLevel(next.LogLevel); err != nil {
+				slog.Error("Failed to change log level", "error", err)
+			}
+		})
+		if cfg.ConfigReloadInterval > 0 {
+			go settings.Watch(cfg.ConfigReloadInterval, stopReload)
+		}
+		slog.Info("Configuration file loaded", "file", cfg.ConfigFile)
+	}
+
+	// SIGHUP reloads certificates and the config file immediately
+	if certs != nil || settings != nil {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if certs != nil {
+					if err := certs.Reload(); err != nil {
+						slog.Error("TLS certificate reload failed, keeping previous certificates", "error", err)
+					} else {
+						slog.Info("TLS certificates reloaded")
+					}
+				}
+				if settings != nil {
+					if err := settings.Reload(); err != nil {
+						slog.Error("Config reload failed, keeping previous configuration", "error", err)
+					} else {
+						slog.Info("Configuration reloaded", "file", cfg.ConfigFile)
+					}
+				}
+			}
+		}()
+	}
+
 	// Start server in goroutine
 	go func() {
-		log.Printf("[INFO] Server listening on port %s", cfg.Port)
-		log.Printf("[INFO] WebSocket endpoint: ws://localhost:%s/ws", cfg.Port)
-		log.Printf("[INFO] REST API endpoint: http://localhost:%s", cfg.Port)
+		httpScheme, wsScheme := "http", "ws"
+		if certs != nil {
+			httpScheme, wsScheme = "https", "wss"
+		}
+		slog.Info("Server listening",
+			"port", cfg.Port,
+			"websocket_url", fmt.Sprintf("%s://localhost:%s/ws", wsScheme, cfg.Port),
+			"rest_url", fmt.Sprintf("%s://localhost:%s", httpScheme, cfg.Port))
 
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("[FATAL] Server error: %v", err)
+		var err error
+		if certs != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatal("Server error", err)
 		}
 	}()
 
@@ -101,19 +311,36 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("[INFO] Shutting down server...")
+	slog.Info("Shutting down server")
 
-	// Create shutdown context with timeout from config
+	// One deadline from config covers the whole shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
-	// Shutdown pub/sub engine first (closes all connections)
-	engine.Shutdown()
+	// Shutdown pub/sub engine first: refuses new connections and publishes,
+	// then drains clients until the deadline
+	engine.ShutdownContext(ctx)
 
-	// Shutdown HTTP server
+	// Shutdown HTTP server with whatever time the drain left
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("[ERROR] Server forced to shutdown: %v", err)
+		slog.Error("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("[INFO] Server shutdown complete")
+	// Close the audit log after the last connection has been recorded
+	if err := auditLog.Close(); err != nil {
+		slog.Error("Failed to close audit log", "error", err)
+	}
+
+	// Flush spans still waiting to be exported
+	if err := traceProvider.Shutdown(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+
+	slog.Info("Server shutdown complete")
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
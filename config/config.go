@@ -1,14 +1,25 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
-	"strconv"
-	"strings"
 	"time"
+
+	"github.com/tarunm/pubsub-system/internal/admission"
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/logging"
+	"github.com/tarunm/pubsub-system/internal/ratelimit"
+	"github.com/tarunm/pubsub-system/internal/tlsconfig"
+	"github.com/tarunm/pubsub-system/internal/tracing"
 )
 
 // Config holds application configuration
 type Config struct {
+	// Config File Configuration
+	ConfigFile           string        // YAML file settings are read from (empty = environment variables only)
+	ConfigReloadInterval time.Duration // How often the config file is checked for changes (0 = SIGHUP only)
+
 	// Server Configuration
 	Port    string
 	GinMode string
@@ -17,6 +28,21 @@ type Config struct {
 	RingBufferSize  int // Number of messages to store per topic for replay
 	SubscriberQueue int // Buffer size for each subscriber's message queue
 
+	// Latency Configuration
+	DeliveryTimestamps bool // Stamp published_at and delivered_at on every event written to subscribers
+
+	// Scheduled Delivery Configuration
+	MaxScheduledMessages int           // Max messages parked for future delivery (0 = unlimited)
+	MaxScheduleDelay     time.Duration // Max distance into the future a message can be scheduled (0 = unlimited)
+
+	// Deduplication Configuration
+	DedupWindow     time.Duration // How long published message IDs are remembered per topic
+	DedupWindowSize int           // Max message IDs remembered per topic (0 = disabled)
+
+	// Request/Reply Configuration
+	RequestTimeout    time.Duration // Default time a request waits for a reply
+	MaxRequestTimeout time.Duration // Upper bound for client-supplied request timeouts
+
 	// WebSocket Configuration
 	PingPeriod   time.Duration // How often to send heartbeat pings
 	PongWait     time.Duration // Max time to wait for pong response
@@ -25,66 +51,292 @@ type Config struct {
 	WriteTimeout time.Duration // HTTP write timeout
 	IdleTimeout  time.Duration // HTTP idle timeout
 
+	// TLS Configuration (HTTPS and WSS are served when a certificate and key are set)
+	TLSCertFile       string        // PEM certificate chain
+	TLSKeyFile        string        // PEM private key
+	TLSMinVersion     string        // Minimum TLS version: "1.2" or "1.3"
+	TLSCipherSuites   []string      // TLS 1.2 cipher suite names (empty = Go defaults)
+	TLSClientCAFile   string        // PEM CA bundle for verifying client certificates (empty = no mTLS)
+	TLSClientAuth     string        // "require" or "optional" client certificates when a client CA is set
+	TLSReloadInterval time.Duration // How often certificate files are checked for changes (0 = SIGHUP only)
+
 	// Shutdown Configuration
 	ShutdownTimeout time.Duration // Max time to wait for graceful shutdown
 
 	// Authentication Configuration
 	AuthEnabled bool     // Enable/disable API key authentication
 	APIKeys     []string // Valid API keys for authentication
+
+	// JWT Configuration (bearer tokens are accepted when a secret or JWKS file is set)
+	JWTSecret   string // Shared secret for HS256 tokens
+	JWTJWKSFile string // Local JWKS file with RS256/ES256 public keys
+	JWTIssuer   string // Required "iss" claim (empty = not checked)
+	JWTAudience string // Required "aud" claim (empty = not checked)
+
+	// Key Management Configuration (the admin API is enabled when admin keys are set)
+	AdminAPIKeys    []string // API keys allowed to use the /admin endpoints
+	APIKeyStoreFile string   // JSON file holding hashed keys created through the admin API
+
+	// Access Control Configuration
+	ACLFile string // JSON policy file granting topic actions to principals (empty = no ACL)
+
+	// Tenant Configuration
+	TenantsFile string // JSON file defining tenants, their API keys and limits (empty = single tenant)
+
+	// Origin Configuration
+	AllowedOrigins []string // Browser origins allowed for WebSocket and REST (empty = same origin only, "*" = any)
+
+	// Rate Limit Configuration (0 = unlimited)
+	PublishRateLimit         int // Publishes per second across all clients
+	PublishByteLimit         int // Payload bytes per second across all clients
+	PublishRateLimitPerKey   int // Publishes per second per API key (or client when auth is disabled)
+	PublishByteLimitPerKey   int // Payload bytes per second per API key
+	PublishRateLimitPerTopic int // Publishes per second per topic
+	PublishByteLimitPerTopic int // Payload bytes per second per topic
+	ConnectRateLimit         int // WebSocket connection attempts per second across all clients
+	ConnectRateLimitPerIP    int // WebSocket connection attempts per second per client IP
+
+	// Connection Limit Configuration (0 = unlimited)
+	MaxConnections            int // Open WebSocket connections across the server
+	MaxConnectionsPerKey      int // Open WebSocket connections per API key or token subject
+	MaxConnectionsPerIP       int // Open WebSocket connections per client IP
+	MaxSubscriptionsPerClient int // Topics a single connection may subscribe to
+
+	// Audit Log Configuration
+	AuditLogFile       string // JSON lines file for audit events (empty = no file)
+	AuditLogMaxSize    int64  // Bytes before the audit file is rotated (0 = never rotate)
+	AuditLogMaxBackups int    // Rotated audit files to keep
+	AuditLogStdout     bool   // Also write audit events to standard output
+
+	// Metrics Configuration
+	MetricsEnabled bool // Serve Prometheus metrics at /metrics
+
+	// Tracing Configuration
+	TracingExporter     string  // "otlp", "file" or empty to disable tracing
+	TracingOTLPEndpoint string  // host:port of the OTLP/HTTP receiver
+	TracingOTLPInsecure bool    // Send OTLP over plain HTTP
+	TracingFile         string  // Span output file for the file exporter
+	TracingServiceName  string  // service.name reported with every span
+	TracingSampleRatio  float64 // Fraction of new traces sampled
+
+	// Logging Configuration
+	LogLevel            string // debug, info, warn or error
+	LogFormat           string // text or json
+	LogSampleInitial    int    // Identical log records per second before sampling (0 = no sampling)
+	LogSampleThereafter int    // Beyond the initial records, log every Nth
+
+	settings map[string]string // Value each setting was loaded from ("" = default), for detecting changes
 }
 
-// LoadConfig loads configuration from environment variables with defaults
+// LoadConfig loads configuration from the file named by CONFIG_FILE, if any,
+// with environment variables taking precedence and defaults for the rest
 // All timing values can be overridden via environment variables
-func LoadConfig() *Config {
-	return &Config{
+func LoadConfig() (*Config, error) {
+	return Load(os.Getenv("CONFIG_FILE"))
+}
+
+// Load loads configuration from the YAML file at path (empty = environment variables only)
+// Environment variables override the file. Unparsable values, unknown file keys and
+// values that fail Validate are all reported in the returned error.
+func Load(path string) (*Config, error) {
+	s, err := newSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		// Config File
+		ConfigFile:           path,
+		ConfigReloadInterval: s.duration("CONFIG_RELOAD_INTERVAL_SEC", 10) * time.Second,
+
 		// Server
-		Port:    getEnv("PORT", "8080"),
-		GinMode: getEnv("GIN_MODE", "release"),
+		Port:    s.string("PORT", "8080"),
+		GinMode: s.string("GIN_MODE", "release"),
 
 		// PubSub
-		RingBufferSize:  getEnvInt("RING_BUFFER_SIZE", 100),
-		SubscriberQueue: getEnvInt("SUBSCRIBER_QUEUE_SIZE", 100),
+		RingBufferSize:  s.int("RING_BUFFER_SIZE", 100),
+		SubscriberQueue: s.int("SUBSCRIBER_QUEUE_SIZE", 100),
+
+		// Latency
+		DeliveryTimestamps: s.bool("DELIVERY_TIMESTAMPS", false),
+
+		// Scheduled Delivery
+		MaxScheduledMessages: s.int("MAX_SCHEDULED_MESSAGES", 10000),
+		MaxScheduleDelay:     s.duration("MAX_SCHEDULE_DELAY_SEC", 7*24*3600) * time.Second,
+
+		// Deduplication
+		DedupWindow:     s.duration("DEDUP_WINDOW_SEC", 300) * time.Second,
+		DedupWindowSize: s.int("DEDUP_WINDOW_SIZE", 10000),
+
+		// Request/Reply
+		RequestTimeout:    time.Duration(s.int("REQUEST_TIMEOUT_MS", 5000)) * time.Millisecond,
+		MaxRequestTimeout: time.Duration(s.int("MAX_REQUEST_TIMEOUT_MS", 60000)) * time.Millisecond,
 
 		// WebSocket Timeouts
-		PingPeriod: getEnvDuration("PING_PERIOD_SEC", 30) * time.Second,
-		PongWait:   getEnvDuration("PONG_WAIT_SEC", 60) * time.Second,
-		WriteWait:  getEnvDuration("WRITE_WAIT_SEC", 10) * time.Second,
+		PingPeriod: s.duration("PING_PERIOD_SEC", 30) * time.Second,
+		PongWait:   s.duration("PONG_WAIT_SEC", 60) * time.Second,
+		WriteWait:  s.duration("WRITE_WAIT_SEC", 10) * time.Second,
 
 		// HTTP Timeouts
-		ReadTimeout:  getEnvDuration("READ_TIMEOUT_SEC", 15) * time.Second,
-		WriteTimeout: getEnvDuration("WRITE_TIMEOUT_SEC", 15) * time.Second,
-		IdleTimeout:  getEnvDuration("IDLE_TIMEOUT_SEC", 0) * time.Second, // 0 = no timeout for WebSocket connections
+		ReadTimeout:  s.duration("READ_TIMEOUT_SEC", 15) * time.Second,
+		WriteTimeout: s.duration("WRITE_TIMEOUT_SEC", 15) * time.Second,
+		IdleTimeout:  s.duration("IDLE_TIMEOUT_SEC", 0) * time.Second, // 0 = no timeout for WebSocket connections
+
+		// TLS
+		TLSCertFile:       s.string("TLS_CERT_FILE", ""),
+		TLSKeyFile:        s.string("TLS_KEY_FILE", ""),
+		TLSMinVersion:     s.string("TLS_MIN_VERSION", "1.2"),
+		TLSCipherSuites:   s.slice("TLS_CIPHER_SUITES", []string{}),
+		TLSClientCAFile:   s.string("TLS_CLIENT_CA_FILE", ""),
+		TLSClientAuth:     s.string("TLS_CLIENT_AUTH", ""),
+		TLSReloadInterval: s.duration("TLS_RELOAD_INTERVAL_SEC", 10) * time.Second,
 
 		// Shutdown
-		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT_SEC", 10) * time.Second,
+		ShutdownTimeout: s.duration("SHUTDOWN_TIMEOUT_SEC", 10) * time.Second,
 
 		// Authentication
-		AuthEnabled: getEnvBool("AUTH_ENABLED", true),
-		APIKeys:     getEnvSlice("API_KEYS", []string{"test-api-key"}),
+		AuthEnabled: s.bool("AUTH_ENABLED", true),
+		APIKeys:     s.slice("API_KEYS", []string{"test-api-key"}),
+
+		// JWT
+		JWTSecret:   s.string("JWT_HS256_SECRET", ""),
+		JWTJWKSFile: s.string("JWT_JWKS_FILE", ""),
+		JWTIssuer:   s.string("JWT_ISSUER", ""),
+		JWTAudience: s.string("JWT_AUDIENCE", ""),
+
+		// Key Management
+		AdminAPIKeys:    s.slice("ADMIN_API_KEYS", []string{}),
+		APIKeyStoreFile: s.string("API_KEY_STORE_FILE", "api_keys.json"),
+
+		// Access Control
+		ACLFile: s.string("ACL_FILE", ""),
+
+		// Tenants
+		TenantsFile: s.string("TENANTS_FILE", ""),
+
+		// Origins
+		AllowedOrigins: s.slice("ALLOWED_ORIGINS", []string{}),
+
+		// Rate Limits
+		PublishRateLimit:         s.int("PUBLISH_RATE_LIMIT", 0),
+		PublishByteLimit:         s.int("PUBLISH_BYTE_LIMIT", 0),
+		PublishRateLimitPerKey:   s.int("PUBLISH_RATE_LIMIT_PER_KEY", 0),
+		PublishByteLimitPerKey:   s.int("PUBLISH_BYTE_LIMIT_PER_KEY", 0),
+		PublishRateLimitPerTopic: s.int("PUBLISH_RATE_LIMIT_PER_TOPIC", 0),
+		PublishByteLimitPerTopic: s.int("PUBLISH_BYTE_LIMIT_PER_TOPIC", 0),
+		ConnectRateLimit:         s.int("CONNECT_RATE_LIMIT", 0),
+		ConnectRateLimitPerIP:    s.int("CONNECT_RATE_LIMIT_PER_IP", 0),
+
+		// Connection Limits
+		MaxConnections:            s.int("MAX_CONNECTIONS", 0),
+		MaxConnectionsPerKey:      s.int("MAX_CONNECTIONS_PER_KEY", 0),
+		MaxConnectionsPerIP:       s.int("MAX_CONNECTIONS_PER_IP", 0),
+		MaxSubscriptionsPerClient: s.int("MAX_SUBSCRIPTIONS_PER_CLIENT", 0),
+
+		// Audit Log
+		AuditLogFile:       s.string("AUDIT_LOG_FILE", ""),
+		AuditLogMaxSize:    int64(s.int("AUDIT_LOG_MAX_SIZE_MB", 100)) * 1024 * 1024,
+		AuditLogMaxBackups: s.int("AUDIT_LOG_MAX_BACKUPS", 5),
+		AuditLogStdout:     s.bool("AUDIT_LOG_STDOUT", false),
+
+		// Metrics
+		MetricsEnabled: s.bool("METRICS_ENABLED", true),
+
+		// Tracing
+		TracingExporter:     s.string("TRACING_EXPORTER", ""),
+		TracingOTLPEndpoint: s.string("TRACING_OTLP_ENDPOINT", ""),
+		TracingOTLPInsecure: s.bool("TRACING_OTLP_INSECURE", false),
+		TracingFile:         s.string("TRACING_FILE", ""),
+		TracingServiceName:  s.string("TRACING_SERVICE_NAME", "pubsub-system"),
+		TracingSampleRatio:  s.float("TRACING_SAMPLE_RATIO", 1),
+
+		// Logging
+		LogLevel:            s.string("LOG_LEVEL", "info"),
+		LogFormat:           s.string("LOG_FORMAT", "text"),
+		LogSampleInitial:    s.int("LOG_SAMPLE_INITIAL", 100),
+		LogSampleThereafter: s.int("LOG_SAMPLE_THEREAFTER", 100),
+	}
+	cfg.settings = s.values
+
+	if err := errors.Join(s.err(), cfg.Validate()); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// JWTEnabled returns whether bearer token authentication is configured
+func (c *Config) JWTEnabled() bool {
+	return c.JWTSecret != "" || c.JWTJWKSFile != ""
+}
+
+// TLSEnabled returns whether the server listens with TLS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+// TLSOptions returns the configured TLS listener options
+func (c *Config) TLSOptions() tlsconfig.Options {
+	return tlsconfig.Options{
+		CertFile:     c.TLSCertFile,
+		KeyFile:      c.TLSKeyFile,
+		MinVersion:   c.TLSMinVersion,
+		CipherSuites: c.TLSCipherSuites,
+		ClientCAFile: c.TLSClientCAFile,
+		ClientAuth:   c.TLSClientAuth,
+	}
+}
+
+// AuditOptions returns the configured audit log sinks
+func (c *Config) AuditOptions() audit.Options {
+	return audit.Options{
+		File:       c.AuditLogFile,
+		MaxSize:    c.AuditLogMaxSize,
+		MaxBackups: c.AuditLogMaxBackups,
+		Stdout:     c.AuditLogStdout,
+	}
+}
+
+// TracingOptions returns the configured span exporter
+func (c *Config) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Exporter:     c.TracingExporter,
+		OTLPEndpoint: c.TracingOTLPEndpoint,
+		OTLPInsecure: c.TracingOTLPInsecure,
+		File:         c.TracingFile,
+		ServiceName:  c.TracingServiceName,
+		SampleRatio:  c.TracingSampleRatio,
 	}
 }
 
-// getEnv retrieves string environment variable or returns default
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// LoggingOptions returns the configured log level, format and sampling
+func (c *Config) LoggingOptions() logging.Options {
+	return logging.Options{
+		Level:            c.LogLevel,
+		Format:           c.LogFormat,
+		SampleInitial:    c.LogSampleInitial,
+		SampleThereafter: c.LogSampleThereafter,
 	}
-	return defaultValue
 }
 
-// getEnvInt retrieves integer environment variable or returns default
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+// ConnectionLimits returns the configured connection and subscription caps
+func (c *Config) ConnectionLimits() admission.Limits {
+	return admission.Limits{
+		MaxConnections:            c.MaxConnections,
+		MaxConnectionsPerKey:      c.MaxConnectionsPerKey,
+		MaxConnectionsPerIP:       c.MaxConnectionsPerIP,
+		MaxSubscriptionsPerClient: c.MaxSubscriptionsPerClient,
 	}
-	return defaultValue
 }
 
-// getEnvDuration retrieves duration (in seconds) environment variable or returns default
-func getEnvDuration(key string, defaultSeconds int) time.Duration {
-	return time.Duration(getEnvInt(key, defaultSeconds))
+// RateLimits returns the configured publish and connection rate limits
+func (c *Config) RateLimits() ratelimit.Config {
+	return ratelimit.Config{
+		PublishGlobal:   ratelimit.Rule{Messages: float64(c.PublishRateLimit), Bytes: float64(c.PublishByteLimit)},
+		PublishPerKey:   ratelimit.Rule{Messages: float64(c.PublishRateLimitPerKey), Bytes: float64(c.PublishByteLimitPerKey)},
+		PublishPerTopic: ratelimit.Rule{Messages: float64(c.PublishRateLimitPerTopic), Bytes: float64(c.PublishByteLimitPerTopic)},
+		ConnectGlobal:   float64(c.ConnectRateLimit),
+		ConnectPerIP:    float64(c.ConnectRateLimitPerIP),
+	}
 }
 
 // GetRingBufferSize returns the ring buffer size configuration
@@ -97,6 +349,46 @@ func (c *Config) GetSubscriberQueue() int {
 	return c.SubscriberQueue
 }
 
+// GetMaxScheduledMessages returns the scheduled message capacity
+func (c *Config) GetMaxScheduledMessages() int {
+	return c.MaxScheduledMessages
+}
+
+// GetMaxScheduleDelay returns the maximum schedule delay
+func (c *Config) GetMaxScheduleDelay() time.Duration {
+	return c.MaxScheduleDelay
+}
+
+// GetDedupWindow returns how long message IDs are remembered for deduplication
+func (c *Config) GetDedupWindow() time.Duration {
+	return c.DedupWindow
+}
+
+// GetDedupWindowSize returns the max number of message IDs remembered per topic
+func (c *Config) GetDedupWindowSize() int {
+	return c.DedupWindowSize
+}
+
+// GetDeliveryTimestamps returns whether events are stamped with publish and delivery times
+func (c *Config) GetDeliveryTimestamps() bool {
+	return c.DeliveryTimestamps
+}
+
+// GetShutdownTimeout returns how long shutdown waits for clients to drain
+func (c *Config) GetShutdownTimeout() time.Duration {
+	return c.ShutdownTimeout
+}
+
+// GetRequestTimeout returns the default request/reply timeout
+func (c *Config) GetRequestTimeout() time.Duration {
+	return c.RequestTimeout
+}
+
+// GetMaxRequestTimeout returns the maximum request/reply timeout
+func (c *Config) GetMaxRequestTimeout() time.Duration {
+	return c.MaxRequestTimeout
+}
+
 // GetPingPeriod returns the ping period duration
 func (c *Config) GetPingPeriod() time.Duration {
 	return c.PingPeriod
@@ -111,30 +403,3 @@ func (c *Config) GetPongWait() time.Duration {
 func (c *Config) GetWriteWait() time.Duration {
 	return c.WriteWait
 }
-
-// getEnvBool retrieves boolean environment variable or returns default
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
-		}
-	}
-	return defaultValue
-}
-
-// getEnvSlice retrieves comma-separated string environment variable and returns as slice
-func getEnvSlice(key string, defaultValue []string) []string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-
-	parts := strings.Split(value, ",")
-	result := make([]string, 0, len(parts))
-	for _, part := range parts {
-		if trimmed := strings.TrimSpace(part); trimmed != "" {
-			result = append(result, trimmed)
-		}
-	}
-	return result
-}
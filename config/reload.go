@@ -0,0 +1,114 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reloadable lists the settings a Reloader applies without a restart
+var reloadable = map[string]bool{
+	"API_KEYS":                     true,
+	"LOG_LEVEL":                    true,
+	"PUBLISH_RATE_LIMIT":           true,
+	"PUBLISH_BYTE_LIMIT":           true,
+	"PUBLISH_RATE_LIMIT_PER_KEY":   true,
+	"PUBLISH_BYTE_LIMIT_PER_KEY":   true,
+	"PUBLISH_RATE_LIMIT_PER_TOPIC": true,
+	"PUBLISH_BYTE_LIMIT_PER_TOPIC": true,
+	"CONNECT_RATE_LIMIT":           true,
+	"CONNECT_RATE_LIMIT_PER_IP":    true,
+}
+
+// Reloader loads the config file again when asked or when it changes
+// Only API keys, rate limits and the log level are applied to the running server;
+// other changes are logged and take effect on the next restart
+type Reloader struct {
+	started *Config       // Configuration the server started with
+	apply   func(*Config) // Applies the reloadable settings of a new configuration
+	modTime time.Time     // Modification time of the file when last loaded
+	mu      sync.Mutex    // Serializes reloads
+}
+
+// NewReloader creates a reloader for the file cfg was loaded from
+// apply is called with every configuration that loads and validates
+func NewReloader(cfg *Config, apply func(*Config)) *Reloader {
+	r := &Reloader{started: cfg, apply: apply}
+	if info, err := os.Stat(cfg.ConfigFile); err == nil {
+		r.modTime = info.ModTime()
+	}
+	return r
+}
+
+// Reload reads the config file and environment variables again
+// On error the running configuration is left unchanged
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Stat before loading so a write during the load is picked up by the next check
+	info, statErr := os.Stat(r.started.ConfigFile)
+
+	next, err := Load(r.started.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if statErr == nil {
+		r.modTime = info.ModTime()
+	}
+
+	if pending := r.started.restartRequired(next); len(pending) > 0 {
+		slog.Warn("Config changes require a restart", "settings", strings.Join(pending, ","))
+	}
+	r.apply(next)
+	return nil
+}
+
+// Watch reloads the configuration whenever the file changes, until stop is closed
+func (r *Reloader) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.Error("Config reload failed, keeping previous configuration", "error", err)
+			} else {
+				slog.Info("Configuration reloaded", "file", r.started.ConfigFile)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// changed reports whether the file was modified since it was last loaded
+func (r *Reloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.started.ConfigFile)
+	if err != nil {
+		return false // Possibly mid-replacement; check again next tick
+	}
+	return !info.ModTime().Equal(r.modTime)
+}
+
+// restartRequired returns the settings that differ in next but cannot be applied at runtime
+func (c *Config) restartRequired(next *Config) []string {
+	var pending []string
+	for key, value := range next.settings {
+		if !reloadable[key] && c.settings[key] != value {
+			pending = append(pending, key)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// source resolves settings from environment variables, then the config file, then defaults
+// Values that cannot be parsed are collected as errors instead of falling back to the default
+type source struct {
+	file   map[string]string // Config file values keyed by environment variable name
+	values map[string]string // Resolved value of every setting looked up ("" = default)
+	errs   []error
+}
+
+// newSource reads the YAML config file at path (empty = environment variables only)
+// File keys are the environment variable names in lower case, e.g. ring_buffer_size
+func newSource(path string) (*source, error) {
+	s := &source{
+		file:   make(map[string]string),
+		values: make(map[string]string),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	for key, value := range settings {
+		name := strings.ToUpper(key)
+		text, err := fileValue(value)
+		if err != nil {
+			s.errs = append(s.errs, fmt.Errorf("%s in %s: %w", key, path, err))
+			continue
+		}
+		s.file[name] = text
+	}
+	return s, nil
+}
+
+// fileValue converts a YAML scalar or list of scalars to the text form used by environment variables
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, isList := item.([]any); isList {
+				return "", errors.New("nested lists are not supported")
+			}
+			text, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("expected a value or a list, got %T", value)
+	}
+}
+
+// lookup returns the value of a setting from the environment or the config file
+// An empty environment variable counts as unset
+func (s *source) lookup(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		value, ok = s.file[key]
+	}
+	if ok {
+		s.values[key] = value
+	} else {
+		s.values[key] = ""
+	}
+	return value, ok
+}
+
+// invalid records that a setting's value could not be parsed
+func (s *source) invalid(key, value, expected string) {
+	s.errs = append(s.errs, fmt.Errorf("%s=%q: expected %s", key, value, expected))
+}
+
+// string retrieves a string setting or returns default
+func (s *source) string(key, defaultValue string) string {
+	if value, ok := s.lookup(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// int retrieves an integer setting or returns default
+func (s *source) int(key string, defaultValue int) int {
+	value, ok := s.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		s.invalid(key, value, "an integer")
+		return defaultValue
+	}
+	return intValue
+}
+
+// float retrieves a float setting or returns default
+func (s *source) float(key string, defaultValue float64) float64 {
+	value, ok := s.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		s.invalid(key, value, "a number")
+		return defaultValue
+	}
+	return floatValue
+}
+
+// bool retrieves a boolean setting or returns default
+func (s *source) bool(key string, defaultValue bool) bool {
+	value, ok := s.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		s.invalid(key, value, "true or false")
+		return defaultValue
+	}
+	return boolValue
+}
+
+// duration retrieves a duration setting (in seconds) or returns default
+func (s *source) duration(key string, defaultSeconds int) time.Duration {
+	return time.Duration(s.int(key, defaultSeconds))
+}
+
+// slice retrieves a comma-separated setting, or a list in the config file, or returns default
+// An empty value in the config file is an empty list
+func (s *source) slice(key string, defaultValue []string) []string {
+	value, ok := s.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// err returns the parse errors and config file keys that match no setting
+func (s *source) err() error {
+	var unknown []string
+	for key := range s.file {
+		if _, known := s.values[key]; !known {
+			unknown = append(unknown, strings.ToLower(key))
+		}
+	}
+	sort.Strings(unknown)
+
+	errs := s.errs
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("unknown setting %q in config file", key))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tarunm/pubsub-system/internal/logging"
+	"github.com/tarunm/pubsub-system/internal/tracing"
+)
+
+// Validate checks that settings are within range and consistent with each other
+// Every problem found is reported, named by its environment variable
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Port)
+	check(err == nil && port > 0 && port <= 65535, "PORT must be between 1 and 65535, got %q", c.Port)
+	check(c.GinMode == "debug" || c.GinMode == "release" || c.GinMode == "test",
+		"GIN_MODE must be debug, release or test, got %q", c.GinMode)
+
+	check(c.RingBufferSize > 0, "RING_BUFFER_SIZE must be at least 1, got %d", c.RingBufferSize)
+	check(c.SubscriberQueue > 0, "SUBSCRIBER_QUEUE_SIZE must be at least 1, got %d", c.SubscriberQueue)
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{"MAX_SCHEDULED_MESSAGES", c.MaxScheduledMessages},
+		{"DEDUP_WINDOW_SIZE", c.DedupWindowSize},
+		{"PUBLISH_RATE_LIMIT", c.PublishRateLimit},
+		{"PUBLISH_BYTE_LIMIT", c.PublishByteLimit},
+		{"PUBLISH_RATE_LIMIT_PER_KEY", c.PublishRateLimitPerKey},
+		{"PUBLISH_BYTE_LIMIT_PER_KEY", c.PublishByteLimitPerKey},
+		{"PUBLISH_RATE_LIMIT_PER_TOPIC", c.PublishRateLimitPerTopic},
+		{"PUBLISH_BYTE_LIMIT_PER_TOPIC", c.PublishByteLimitPerTopic},
+		{"CONNECT_RATE_LIMIT", c.ConnectRateLimit},
+		{"CONNECT_RATE_LIMIT_PER_IP", c.ConnectRateLimitPerIP},
+		{"MAX_CONNECTIONS", c.MaxConnections},
+		{"MAX_CONNECTIONS_PER_KEY", c.MaxConnectionsPerKey},
+		{"MAX_CONNECTIONS_PER_IP", c.MaxConnectionsPerIP},
+		{"MAX_SUBSCRIPTIONS_PER_CLIENT", c.MaxSubscriptionsPerClient},
+		{"AUDIT_LOG_MAX_BACKUPS", c.AuditLogMaxBackups},
+		{"LOG_SAMPLE_INITIAL", c.LogSampleInitial},
+		{"LOG_SAMPLE_THEREAFTER", c.LogSampleThereafter},
+	} {
+		check(setting.value >= 0, "%s must not be negative, got %d", setting.name, setting.value)
+	}
+	check(c.AuditLogMaxSize >= 0, "AUDIT_LOG_MAX_SIZE_MB must not be negative, got %d", c.AuditLogMaxSize/(1024*1024))
+
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"CONFIG_RELOAD_INTERVAL_SEC", c.ConfigReloadInterval},
+		{"MAX_SCHEDULE_DELAY_SEC", c.MaxScheduleDelay},
+		{"DEDUP_WINDOW_SEC", c.DedupWindow},
+		{"MAX_REQUEST_TIMEOUT_MS", c.MaxRequestTimeout},
+		{"READ_TIMEOUT_SEC", c.ReadTimeout},
+		{"WRITE_TIMEOUT_SEC", c.WriteTimeout},
+		{"IDLE_TIMEOUT_SEC", c.IdleTimeout},
+		{"TLS_RELOAD_INTERVAL_SEC", c.TLSReloadInterval},
+		{"SHUTDOWN_TIMEOUT_SEC", c.ShutdownTimeout},
+	} {
+		check(setting.value >= 0, "%s must not be negative, got %s", setting.name, setting.value)
+	}
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"REQUEST_TIMEOUT_MS", c.RequestTimeout},
+		{"PING_PERIOD_SEC", c.PingPeriod},
+		{"PONG_WAIT_SEC", c.PongWait},
+		{"WRITE_WAIT_SEC", c.WriteWait},
+	} {
+		check(setting.value > 0, "%s must be positive, got %s", setting.name, setting.value)
+	}
+	check(c.PingPeriod < c.PongWait, "PING_PERIOD_SEC (%s) must be less than PONG_WAIT_SEC (%s)", c.PingPeriod, c.PongWait)
+
+	check(c.TracingExporter == tracing.ExporterNone || c.TracingExporter == tracing.ExporterOTLP || c.TracingExporter == tracing.ExporterFile,
+		"TRACING_EXPORTER must be %s, %s or empty, got %q", tracing.ExporterOTLP, tracing.ExporterFile, c.TracingExporter)
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1,
+		"TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.TracingSampleRatio)
+
+	if _, err := logging.New(io.Discard, c.LoggingOptions()); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,165 @@
+package admission
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+var (
+	// ErrServerFull is returned when the server has reached its total connection limit
+	ErrServerFull = errors.New("server connection limit reached")
+
+	// ErrIPLimit is returned when a client IP has reached its connection limit
+	ErrIPLimit = errors.New("connection limit reached for client IP")
+
+	// ErrKeyLimit is returned when a credential has reached its connection limit
+	ErrKeyLimit = errors.New("connection limit reached for API key")
+)
+
+// Limits caps WebSocket connections and subscriptions. Zero values mean unlimited.
+type Limits struct {
+	MaxConnections            int // Open connections across the server
+	MaxConnectionsPerKey      int // Open connections per API key or token subject
+	MaxConnectionsPerIP       int // Open connections per client IP
+	MaxSubscriptionsPerClient int // Topics a single connection may subscribe to
+}
+
+// Controller admits WebSocket connections within the configured limits and tracks usage
+// A nil controller admits everything
+type Controller struct {
+	limits Limits
+	total  int
+	perIP  map[string]int
+	perKey map[string]int
+	mu     sync.Mutex
+}
+
+// NewController creates a controller enforcing limits
+func NewController(limits Limits) *Controller {
+	return &Controller{
+		limits: limits,
+		perIP:  make(map[string]int),
+		perKey: make(map[string]int),
+	}
+}
+
+// Admit reserves a connection slot for a client IP
+// Every successful call must be paired with Release
+func (c *Controller) Admit(ip string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limits.MaxConnections > 0 && c.total >= c.limits.MaxConnections {
+		return ErrServerFull
+	}
+	if c.limits.MaxConnectionsPerIP > 0 && c.perIP[ip] >= c.limits.MaxConnectionsPerIP {
+		return ErrIPLimit
+	}
+	c.total++
+	c.perIP[ip]++
+	return nil
+}
+
+// Release frees a connection slot reserved by Admit
+func (c *Controller) Release(ip string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.total > 0 {
+		c.total--
+	}
+	decrement(c.perIP, ip)
+}
+
+// AdmitKey reserves a connection slot for an authenticated credential
+// Every successful call must be paired with ReleaseKey
+func (c *Controller) AdmitKey(key string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limits.MaxConnectionsPerKey > 0 && c.perKey[key] >= c.limits.MaxConnectionsPerKey {
+		return ErrKeyLimit
+	}
+	c.perKey[key]++
+	return nil
+}
+
+// ReleaseKey frees a connection slot reserved by AdmitKey
+func (c *Controller) ReleaseKey(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	decrement(c.perKey, key)
+}
+
+// MaxSubscriptions returns the subscription limit per connection (0 = unlimited)
+func (c *Controller) MaxSubscriptions() int {
+	if c == nil {
+		return 0
+	}
+	return c.limits.MaxSubscriptionsPerClient
+}
+
+// CheckCapacity returns ErrServerFull when the total connection limit has been reached
+func (c *Controller) CheckCapacity() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limits.MaxConnections > 0 && c.total >= c.limits.MaxConnections {
+		return fmt.Errorf("%w (%d/%d)", ErrServerFull, c.total, c.limits.MaxConnections)
+	}
+	return nil
+}
+
+// Usage returns current connection counts alongside the configured limits
+func (c *Controller) Usage() models.ConnectionUsage {
+	if c == nil {
+		return models.ConnectionUsage{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return models.ConnectionUsage{
+		Active:                    c.total,
+		ClientIPs:                 len(c.perIP),
+		APIKeys:                   len(c.perKey),
+		MaxConnections:            c.limits.MaxConnections,
+		MaxConnectionsPerKey:      c.limits.MaxConnectionsPerKey,
+		MaxConnectionsPerIP:       c.limits.MaxConnectionsPerIP,
+		MaxSubscriptionsPerClient: c.limits.MaxSubscriptionsPerClient,
+	}
+}
+
+// decrement lowers a count, removing the entry when it reaches zero
+// Must be called with the lock held
+func decrement(counts map[string]int, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audited actions
+const (
+	ActionAuth              = "auth"               // Authentication attempt (WebSocket auth, rejected REST credentials)
+	ActionTopicCreate       = "topic.create"       // POST /topics
+	ActionTopicDelete       = "topic.delete"       // DELETE /topics/:name
+	ActionScheduledCancel   = "scheduled.cancel"   // DELETE /scheduled/:id
+	ActionKeyCreate         = "key.create"         // POST /admin/keys
+	ActionKeyUpdate         = "key.update"         // PATCH /admin/keys/:id
+	ActionKeyRevoke         = "key.revoke"         // DELETE /admin/keys/:id
+	ActionClientClose       = "client.close"       // DELETE /admin/clients/:id
+	ActionClientUnsubscribe = "client.unsubscribe" // DELETE /admin/clients/:id/subscriptions/:topic
+	ActionDisconnect        = "client.disconnect"  // Server closed a client connection
+)
+
+// Outcomes of an audited action
+const (
+	OutcomeSuccess = "success" // The action was performed
+	OutcomeDenied  = "denied"  // Credentials were missing or lacked permission
+	OutcomeFailure = "failure" // The action was permitted but could not be performed
+)
+
+// Event is a single audit record
+type Event struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Outcome   string    `json:"outcome"`
+	Principal string    `json:"principal,omitempty"` // Principal.String(); empty when unauthenticated
+	Tenant    string    `json:"tenant,omitempty"`
+	Target    string    `json:"target,omitempty"` // Topic, key ID, client ID or route acted on
+	ClientIP  string    `json:"client_ip,omitempty"`
+	Reason    string    `json:"reason,omitempty"` // Error code or disconnect reason
+}
+
+// Sink receives audit events
+// Write is called for one event at a time
+type Sink interface {
+	Write(event Event) error
+	Close() error
+}
+
+// Options configures the built-in sinks
+type Options struct {
+	File       string // JSON lines file (empty = no file sink)
+	MaxSize    int64  // Bytes before the file is rotated (0 = never rotate)
+	MaxBackups int    // Rotated files to keep
+	Stdout     bool   // Also write JSON lines to standard output
+}
+
+// Logger records audit events to one or more sinks
+type Logger struct {
+	sinks []Sink
+	mu    sync.Mutex
+}
+
+// New creates a logger that writes every event to each sink
+func New(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+// Open creates a logger with the sinks in opts, or returns nil when none are configured
+func Open(opts Options) (*Logger, error) {
+	var sinks []Sink
+	if opts.File != "" {
+		file, err := NewFileSink(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, file)
+	}
+	if opts.Stdout {
+		sinks = append(sinks, NewWriterSink(nopCloser{os.Stdout}))
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return New(sinks...), nil
+}
+
+// Record writes an event to every sink, stamping the current time if unset
+// Sink errors are logged and never returned to the caller
+func (l *Logger) Record(event Event) {
+	if l == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, sink := range l.sinks {
+		if err := sink.Write(event); err != nil {
+			slog.Error("Failed to write audit event", "action", event.Action, "error", err)
+		}
+	}
+}
+
+// Close flushes and closes every sink
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// nopCloser keeps the writer sink from closing standard output
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
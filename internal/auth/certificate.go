@@ -0,0 +1,19 @@
+package auth
+
+import "crypto/tls"
+
+// CertificatePrincipal returns the principal for a verified TLS client certificate
+// The certificate's common name becomes the principal ID, falling back to the full subject
+// Returns nil when the connection did not present a verified certificate
+func CertificatePrincipal(state *tls.ConnectionState) *Principal {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := state.VerifiedChains[0][0]
+	id := cert.Subject.CommonName
+	if id == "" {
+		id = cert.Subject.String()
+	}
+	return &Principal{ID: id, Method: MethodCertificate}
+}
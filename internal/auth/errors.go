@@ -5,11 +5,22 @@ const (
 	ErrCodeUnauthorized  = "UNAUTHORIZED"
 	ErrCodeInvalidAPIKey = "INVALID_API_KEY"
 	ErrCodeMissingAPIKey = "MISSING_API_KEY"
+	ErrCodeInvalidToken  = "INVALID_TOKEN"
+	ErrCodeTokenExpired  = "TOKEN_EXPIRED"
+	ErrCodeForbidden     = "FORBIDDEN"
+	ErrCodeKeyExpired    = "KEY_EXPIRED"
+	ErrCodeKeyRevoked    = "KEY_REVOKED"
 )
 
 // Error messages
 const (
-	ErrMsgMissingAPIKey = "Missing X-API-Key header"
+	ErrMsgMissingAPIKey = "Missing X-API-Key or Authorization header"
 	ErrMsgInvalidAPIKey = "Invalid or expired API key"
 	ErrMsgUnauthorized  = "Authentication required"
+	ErrMsgInvalidToken  = "Invalid bearer token"
+	ErrMsgTokenExpired  = "Bearer token has expired"
+	ErrMsgForbidden     = "Not permitted for this topic"
+	ErrMsgKeyExpired    = "API key has expired"
+	ErrMsgKeyRevoked    = "API key has been revoked"
+	ErrMsgAdminRequired = "Admin API key required"
 )
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tarunm/pubsub-system/internal/tenant"
+)
+
+var (
+	// ErrInvalidToken is returned when a bearer token fails verification
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrTokenExpired is returned when a bearer token has expired
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// TopicClaims lists the topic patterns a token may publish and subscribe to
+type TopicClaims struct {
+	Publish   []string `json:"publish"`
+	Subscribe []string `json:"subscribe"`
+}
+
+// TokenClaims are the claims accepted in bearer tokens
+// Tokens without a "topics" claim are not restricted to specific topics,
+// and tokens without a "tenant" claim belong to the default tenant
+type TokenClaims struct {
+	jwt.RegisteredClaims
+	Tenant string       `json:"tenant,omitempty"`
+	Topics *TopicClaims `json:"topics,omitempty"`
+}
+
+// JWTVerifier verifies HS256 tokens with a shared secret and RS256/ES256 tokens
+// with public keys loaded from a local JWKS file
+type JWTVerifier struct {
+	hmacSecret []byte
+	publicKeys map[string]interface{} // kid -> *rsa.PublicKey or *ecdsa.PublicKey
+	issuer     string
+	audience   string
+}
+
+// NewJWTVerifier creates a verifier from an HMAC secret and/or a JWKS file path
+// Empty issuer or audience disables the corresponding check
+func NewJWTVerifier(hmacSecret, jwksFile, issuer, audience string) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		publicKeys: make(map[string]interface{}),
+		issuer:     issuer,
+		audience:   audience,
+	}
+	if hmacSecret != "" {
+		v.hmacSecret = []byte(hmacSecret)
+	}
+
+	if jwksFile != "" {
+		keys, err := loadJWKS(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKeys = keys
+	}
+
+	if v.hmacSecret == nil && len(v.publicKeys) == 0 {
+		return nil, errors.New("jwt verifier needs an HS256 secret or a JWKS file")
+	}
+	return v, nil
+}
+
+// Verify validates a token's signature, expiry and optional issuer/audience
+// and returns the principal it describes
+func (v *JWTVerifier) Verify(tokenString string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, v.keyFunc, opts...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	if claims.Tenant != "" && !tenant.ValidName(claims.Tenant) {
+		return nil, fmt.Errorf("%w: invalid tenant", ErrInvalidToken)
+	}
+
+	principal := &Principal{
+		ID:        claims.Subject,
+		Method:    MethodJWT,
+		ExpiresAt: claims.ExpiresAt.Time,
+		Tenant:    claims.Tenant,
+	}
+	if claims.Topics != nil {
+		principal.PublishTopics = nonNil(claims.Topics.Publish)
+		principal.SubscribeTopics = nonNil(claims.Topics.Subscribe)
+	}
+	return principal, nil
+}
+
+// keyFunc selects the verification key for a token based on its algorithm and kid
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case "HS256":
+		if v.hmacSecret == nil {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return v.hmacSecret, nil
+	case "RS256", "ES256":
+		kid, _ := token.Header["kid"].(string)
+		key, exists := v.publicKeys[kid]
+		if !exists {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+// LooksLikeJWT reports whether a credential has the three-part shape of a JWT
+func LooksLikeJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA and EC public keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// loadJWKS reads RSA and P-256 EC public keys from a JWKS file
+func loadJWKS(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS file: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey converts a JWK into an RSA or ECDSA public key
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// nonNil returns an empty slice instead of nil so an empty claim denies all topics
+func nonNil(patterns []string) []string {
+	if patterns == nil {
+		return []string{}
+	}
+	return patterns
+}
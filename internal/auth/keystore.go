@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrKeyNotFound is returned when a managed key ID does not exist
+	ErrKeyNotFound = errors.New("API key not found")
+
+	// ErrKeyRevoked is returned when modifying a key that was already revoked
+	ErrKeyRevoked = errors.New("API key revoked")
+)
+
+// Managed key states
+const (
+	KeyStatusActive  = "active"
+	KeyStatusExpired = "expired"
+	KeyStatusRevoked = "revoked"
+)
+
+// APIKeyRecord describes a managed API key. Only the SHA-256 hash of the key is stored.
+type APIKeyRecord struct {
+	ID        string     `json:"id"`
+	Hash      string     `json:"hash"`
+	Masked    string     `json:"masked"`
+	Label     string     `json:"label"`
+	Tenant    string     `json:"tenant,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	CreatedBy string     `json:"created_by"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Status returns whether the key is active, expired or revoked
+func (r APIKeyRecord) Status() string {
+	switch {
+	case r.RevokedAt != nil:
+		return KeyStatusRevoked
+	case r.ExpiresAt != nil && !time.Now().Before(*r.ExpiresAt):
+		return KeyStatusExpired
+	default:
+		return KeyStatusActive
+	}
+}
+
+// KeyStore holds API keys created at runtime and persists them to a JSON file
+// An empty path keeps keys in memory only
+type KeyStore struct {
+	path   string
+	keys   map[string]*APIKeyRecord // id -> record
+	byHash map[string]*APIKeyRecord // hash -> record
+	mu     sync.RWMutex
+}
+
+// NewKeyStore creates a key store backed by path, loading any existing keys
+func NewKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{
+		path:   path,
+		keys:   make(map[string]*APIKeyRecord),
+		byHash: make(map[string]*APIKeyRecord),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read key store: %w", err)
+	}
+
+	var records []*APIKeyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("parse key store: %w", err)
+	}
+	for _, record := range records {
+		s.keys[record.ID] = record
+		s.byHash[record.Hash] = record
+	}
+	return s, nil
+}
+
+// Create generates a new API key in a tenant and returns it along with its record
+// The plaintext key is not stored and cannot be retrieved again
+func (s *KeyStore) Create(label, tenant, createdBy string, expiresAt *time.Time) (string, APIKeyRecord, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", APIKeyRecord{}, fmt.Errorf("generate key: %w", err)
+	}
+	key := hex.EncodeToString(raw)
+
+	record := &APIKeyRecord{
+		ID:        uuid.New().String(),
+		Hash:      HashKey(key),
+		Masked:    MaskKey(key),
+		Label:     label,
+		Tenant:    tenant,
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[record.ID] = record
+	s.byHash[record.Hash] = record
+	if err := s.save(); err != nil {
+		delete(s.keys, record.ID)
+		delete(s.byHash, record.Hash)
+		return "", APIKeyRecord{}, err
+	}
+	return key, *record, nil
+}
+
+// List returns all managed keys, oldest first
+func (s *KeyStore) List() []APIKeyRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]APIKeyRecord, 0, len(s.keys))
+	for _, record := range s.keys {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	return records
+}
+
+// Revoke permanently disables a key
+func (s *KeyStore) Revoke(id string) (APIKeyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.keys[id]
+	if !exists {
+		return APIKeyRecord{}, ErrKeyNotFound
+	}
+	if record.RevokedAt != nil {
+		return *record, ErrKeyRevoked
+	}
+
+	now := time.Now().UTC()
+	record.RevokedAt = &now
+	if err := s.save(); err != nil {
+		record.RevokedAt = nil
+		return APIKeyRecord{}, err
+	}
+	return *record, nil
+}
+
+// SetExpiry changes when a key expires; nil removes the expiry
+func (s *KeyStore) SetExpiry(id string, expiresAt *time.Time) (APIKeyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.keys[id]
+	if !exists {
+		return APIKeyRecord{}, ErrKeyNotFound
+	}
+	if record.RevokedAt != nil {
+		return *record, ErrKeyRevoked
+	}
+
+	previous := record.ExpiresAt
+	record.ExpiresAt = expiresAt
+	if err := s.save(); err != nil {
+		record.ExpiresAt = previous
+		return APIKeyRecord{}, err
+	}
+	return *record, nil
+}
+
+// Lookup returns the record for a plaintext key if it exists
+func (s *KeyStore) Lookup(key string) (APIKeyRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, exists := s.byHash[HashKey(key)]
+	if !exists {
+		return APIKeyRecord{}, false
+	}
+	return *record, true
+}
+
+// CheckWritable returns an error when changes to the store could not be persisted
+func (s *KeyStore) CheckWritable() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".keys-*.tmp")
+	if err != nil {
+		return fmt.Errorf("key store not writable: %w", err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	return nil
+}
+
+// save writes all records to the backing file atomically
+// Must be called with the lock held
+func (s *KeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	records := make([]*APIKeyRecord, 0, len(s.keys))
+	for _, record := range s.keys {
+		records = append(records, record)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode key store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".keys-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write key store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write key store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write key store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write key store: %w", err)
+	}
+	return nil
+}
+
+// HashKey returns the hex-encoded SHA-256 hash of an API key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,47 +1,154 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tarunm/pubsub-system/internal/audit"
 )
 
-// AuthMiddleware creates a Gin middleware for X-API-Key authentication
+// PrincipalContextKey is the gin context key holding the authenticated *Principal
+const PrincipalContextKey = "principal"
+
+// AuthMiddleware creates a Gin middleware for X-API-Key, bearer token and client certificate authentication
 func AuthMiddleware(validator *APIKeyValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Extract API key from X-API-Key header, falling back to Authorization: Bearer
+		credential := c.GetHeader("X-API-Key")
+		if credential == "" {
+			credential = bearerToken(c.GetHeader("Authorization"))
+		}
+
+		// A verified client certificate identifies the caller when no other credential is given
+		if credential == "" {
+			if principal := CertificatePrincipal(c.Request.TLS); principal != nil {
+				c.Set(PrincipalContextKey, principal)
+				c.Next()
+				return
+			}
+		}
+
 		// If auth is disabled, allow all requests
 		if !validator.IsEnabled() {
+			c.Set(PrincipalContextKey, AnonymousPrincipal())
 			c.Next()
 			return
 		}
 
-		// Extract API key from X-API-Key header
-		apiKey := c.GetHeader("X-API-Key")
+		if credential == "" {
+			validator.recordDenied(c, "", ErrCodeMissingAPIKey)
+			abortUnauthorized(c, ErrCodeMissingAPIKey, ErrMsgMissingAPIKey)
+			return
+		}
+
+		// Validate the credential
+		principal, err := validator.Authenticate(credential)
+		if err != nil {
+			code, message := AuthErrorCode(err)
+			validator.recordDenied(c, "", code)
+			abortUnauthorized(c, code, message)
+			return
+		}
+
+		// Credential is valid, proceed
+		c.Set(PrincipalContextKey, principal)
+		c.Next()
+	}
+}
+
+// AdminMiddleware creates a Gin middleware that only admits admin API keys
+// It applies even when regular authentication is disabled
+func AdminMiddleware(validator *APIKeyValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential := c.GetHeader("X-API-Key")
+		if credential == "" {
+			credential = bearerToken(c.GetHeader("Authorization"))
+		}
 
-		if apiKey == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": gin.H{
-					"code":    ErrCodeMissingAPIKey,
-					"message": ErrMsgMissingAPIKey,
-				},
-			})
-			c.Abort()
+		if credential == "" {
+			validator.recordDenied(c, "", ErrCodeMissingAPIKey)
+			abortUnauthorized(c, ErrCodeMissingAPIKey, ErrMsgMissingAPIKey)
 			return
 		}
 
-		// Validate the API key
-		if !validator.ValidateKey(apiKey) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": gin.H{
-					"code":    ErrCodeInvalidAPIKey,
-					"message": ErrMsgInvalidAPIKey,
-				},
-			})
-			c.Abort()
+		if !validator.IsAdminKey(credential) {
+			validator.recordDenied(c, MethodAPIKey+":"+MaskKey(credential), ErrCodeForbidden)
+			AbortForbidden(c, ErrMsgAdminRequired)
 			return
 		}
 
-		// Key is valid, proceed
+		c.Set(PrincipalContextKey, &Principal{ID: credential, Method: MethodAPIKey})
 		c.Next()
 	}
 }
+
+// GetPrincipal returns the principal stored by AuthMiddleware
+// Returns the anonymous principal for routes that are not protected
+func GetPrincipal(c *gin.Context) *Principal {
+	if value, exists := c.Get(PrincipalContextKey); exists {
+		if principal, ok := value.(*Principal); ok {
+			return principal
+		}
+	}
+	return AnonymousPrincipal()
+}
+
+// AbortForbidden rejects the request with a 403 error body
+func AbortForbidden(c *gin.Context, message string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": gin.H{
+			"code":    ErrCodeForbidden,
+			"message": message,
+		},
+	})
+	c.Abort()
+}
+
+// AuthErrorCode maps an authentication error to an error code and message
+func AuthErrorCode(err error) (string, string) {
+	switch {
+	case errors.Is(err, ErrMissingCredentials):
+		return ErrCodeMissingAPIKey, ErrMsgMissingAPIKey
+	case errors.Is(err, ErrTokenExpired):
+		return ErrCodeTokenExpired, ErrMsgTokenExpired
+	case errors.Is(err, ErrInvalidToken):
+		return ErrCodeInvalidToken, ErrMsgInvalidToken
+	default:
+		return ErrCodeInvalidAPIKey, ErrMsgInvalidAPIKey
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// recordDenied audits and counts a request rejected for missing, invalid or insufficient credentials
+func (v *APIKeyValidator) recordDenied(c *gin.Context, principal, code string) {
+	v.CountFailure("rest", code)
+	v.audit.Record(audit.Event{
+		Action:    audit.ActionAuth,
+		Outcome:   audit.OutcomeDenied,
+		Principal: principal,
+		Target:    c.Request.Method + " " + c.Request.URL.Path,
+		ClientIP:  c.ClientIP(),
+		Reason:    code,
+	})
+}
+
+// abortUnauthorized rejects the request with a 401 error body
+func abortUnauthorized(c *gin.Context, code, message string) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+	c.Abort()
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"strings"
+	"time"
+)
+
+// Authentication methods
+const (
+	MethodNone        = "none"
+	MethodAPIKey      = "api_key"
+	MethodJWT         = "jwt"
+	MethodCertificate = "cert"
+)
+
+// Principal is an authenticated identity and what it is allowed to do
+type Principal struct {
+	ID              string    // API key or token subject
+	Method          string    // How the principal authenticated
+	KeyID           string    // Key store ID for managed API keys (empty otherwise)
+	Tenant          string    // Namespace the principal's topics live in ("" = default)
+	ExpiresAt       time.Time // Zero when the credential does not expire
+	PublishTopics   []string  // Topic patterns the principal may publish to (nil = unrestricted)
+	SubscribeTopics []string  // Topic patterns the principal may subscribe to (nil = unrestricted)
+}
+
+// AnonymousPrincipal returns the principal used when authentication is disabled
+func AnonymousPrincipal() *Principal {
+	return &Principal{ID: "anonymous", Method: MethodNone}
+}
+
+// String returns a log-safe representation that never contains a full API key
+func (p *Principal) String() string {
+	if p.Method == MethodAPIKey {
+		return MethodAPIKey + ":" + MaskKey(p.ID)
+	}
+	return p.Method + ":" + p.ID
+}
+
+// ExpiredErrorCode returns the error code and message sent when the principal's credential expires
+func (p *Principal) ExpiredErrorCode() (string, string) {
+	if p.Method == MethodJWT {
+		return ErrCodeTokenExpired, ErrMsgTokenExpired
+	}
+	return ErrCodeKeyExpired, ErrMsgKeyExpired
+}
+
+// Expired reports whether the principal's credential has expired
+func (p *Principal) Expired() bool {
+	return !p.ExpiresAt.IsZero() && time.Now().After(p.ExpiresAt)
+}
+
+// CanPublish reports whether the principal may publish to a topic
+func (p *Principal) CanPublish(topic string) bool {
+	return p.PublishTopics == nil || matchAny(p.PublishTopics, topic)
+}
+
+// CanSubscribe reports whether the principal may subscribe to a topic
+func (p *Principal) CanSubscribe(topic string) bool {
+	return p.SubscribeTopics == nil || matchAny(p.SubscribeTopics, topic)
+}
+
+// MatchTopic reports whether a topic matches a pattern
+// "*" matches any sequence of characters; all other characters match literally
+func MatchTopic(pattern, topic string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == topic
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(topic, parts[0]) {
+		return false
+	}
+	topic = topic[len(parts[0]):]
+
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		idx := strings.Index(topic, part)
+		if idx < 0 {
+			return false
+		}
+		topic = topic[idx+len(part):]
+	}
+	return strings.HasSuffix(topic, parts[last])
+}
+
+// matchAny reports whether a topic matches any of the patterns
+func matchAny(patterns []string, topic string) bool {
+	for _, pattern := range patterns {
+		if MatchTopic(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskKey hides all but the first four characters of a key
+func MaskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return key[:4] + "****"
+}
@@ -1,28 +1,128 @@
 package auth
 
-import "strings"
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"sync"
 
-// APIKeyValidator validates API keys for authentication
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/metrics"
+)
+
+var (
+	// ErrMissingCredentials is returned when no API key or token is presented
+	ErrMissingCredentials = errors.New("missing credentials")
+
+	// ErrInvalidAPIKey is returned when an API key is not recognised
+	ErrInvalidAPIKey = errors.New("invalid API key")
+)
+
+// APIKeyValidator validates API keys and, when configured, JWT bearer tokens
 type APIKeyValidator struct {
-	validKeys map[string]bool
+	apiKeys   map[string]struct{} // Static keys, replaceable with SetAPIKeys
+	validKeys map[string]string   // Admin and tenant keys: key -> tenant ("" = default tenant)
+	adminKeys []string
+	mu        sync.RWMutex // Guards apiKeys
 	enabled   bool
+	jwt       *JWTVerifier        // nil when bearer tokens are not accepted
+	store     *KeyStore           // nil when keys are not managed at runtime
+	audit     *audit.Logger       // nil when auditing is disabled
+	failures  *metrics.CounterVec // nil when metrics are not collected
 }
 
 // NewAPIKeyValidator creates a new API key validator
 func NewAPIKeyValidator(keys []string, enabled bool) *APIKeyValidator {
-	validKeysMap := make(map[string]bool)
+	v := &APIKeyValidator{
+		validKeys: make(map[string]string),
+		enabled:   enabled,
+	}
+	v.SetAPIKeys(keys)
+	return v
+}
+
+// SetAPIKeys replaces the static API keys
+// Admin, tenant and managed keys are unaffected, and clients that already
+// authenticated with a removed key stay connected
+func (v *APIKeyValidator) SetAPIKeys(keys []string) {
+	apiKeys := make(map[string]struct{})
 	for _, key := range keys {
 		if trimmed := strings.TrimSpace(key); trimmed != "" {
-			validKeysMap[trimmed] = true
+			apiKeys[trimmed] = struct{}{}
 		}
 	}
 
-	return &APIKeyValidator{
-		validKeys: validKeysMap,
-		enabled:   enabled,
+	v.mu.Lock()
+	v.apiKeys = apiKeys
+	v.mu.Unlock()
+}
+
+// isAPIKey reports whether key is one of the static API keys
+func (v *APIKeyValidator) isAPIKey(key string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	_, valid := v.apiKeys[key]
+	return valid
+}
+
+// SetJWTVerifier enables bearer token authentication alongside API keys
+func (v *APIKeyValidator) SetJWTVerifier(verifier *JWTVerifier) {
+	v.jwt = verifier
+}
+
+// SetKeyStore accepts keys managed through the admin API alongside static keys
+func (v *APIKeyValidator) SetKeyStore(store *KeyStore) {
+	v.store = store
+}
+
+// SetAuditLog records rejected REST credentials to logger
+func (v *APIKeyValidator) SetAuditLog(logger *audit.Logger) {
+	v.audit = logger
+}
+
+// SetMetrics counts authentication failures in registry
+func (v *APIKeyValidator) SetMetrics(registry *metrics.Registry) {
+	v.failures = registry.NewCounterVec("pubsub_auth_failures_total",
+		"Rejected authentication attempts by transport (rest, websocket) and error code.", "transport", "code")
+}
+
+// CountFailure records a rejected authentication attempt
+func (v *APIKeyValidator) CountFailure(transport, code string) {
+	v.failures.With(transport, code).Inc()
+}
+
+// SetAdminKeys sets the keys allowed to use the admin API
+// Admin keys are also accepted as regular API keys
+func (v *APIKeyValidator) SetAdminKeys(keys []string) {
+	v.adminKeys = nil
+	for _, key := range keys {
+		if trimmed := strings.TrimSpace(key); trimmed != "" {
+			v.adminKeys = append(v.adminKeys, trimmed)
+			v.validKeys[trimmed] = ""
+		}
+	}
+}
+
+// AddTenantKeys accepts keys that belong to a tenant
+func (v *APIKeyValidator) AddTenantKeys(tenant string, keys []string) {
+	for _, key := range keys {
+		if trimmed := strings.TrimSpace(key); trimmed != "" {
+			v.validKeys[trimmed] = tenant
+		}
 	}
 }
 
+// IsAdminKey reports whether a key may use the admin API
+func (v *APIKeyValidator) IsAdminKey(key string) bool {
+	for _, admin := range v.adminKeys {
+		if subtle.ConstantTimeCompare([]byte(admin), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateKey checks if the provided API key is valid
 func (v *APIKeyValidator) ValidateKey(key string) bool {
 	if !v.enabled {
@@ -33,7 +133,42 @@ func (v *APIKeyValidator) ValidateKey(key string) bool {
 		return false
 	}
 
-	return v.validKeys[key]
+	_, valid := v.validKeys[key]
+	return valid || v.isAPIKey(key)
+}
+
+// Authenticate resolves an API key or JWT bearer token to a principal
+// When auth is disabled every caller is the anonymous principal
+func (v *APIKeyValidator) Authenticate(credential string) (*Principal, error) {
+	if !v.enabled {
+		return AnonymousPrincipal(), nil
+	}
+
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	if v.jwt != nil && LooksLikeJWT(credential) {
+		return v.jwt.Verify(credential)
+	}
+
+	if tenant, valid := v.validKeys[credential]; valid {
+		return &Principal{ID: credential, Method: MethodAPIKey, Tenant: tenant}, nil
+	}
+	if v.isAPIKey(credential) {
+		return &Principal{ID: credential, Method: MethodAPIKey}, nil
+	}
+
+	if v.store != nil {
+		if record, exists := v.store.Lookup(credential); exists && record.Status() == KeyStatusActive {
+			principal := &Principal{ID: credential, Method: MethodAPIKey, KeyID: record.ID, Tenant: record.Tenant}
+			if record.ExpiresAt != nil {
+				principal.ExpiresAt = *record.ExpiresAt
+			}
+			return principal, nil
+		}
+	}
+	return nil, ErrInvalidAPIKey
 }
 
 // IsEnabled returns whether authentication is enabled
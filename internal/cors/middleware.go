@@ -0,0 +1,50 @@
+package cors
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers browsers may send and read on cross-origin REST requests
+const (
+	allowedMethods  = "GET, POST, PATCH, DELETE, OPTIONS"
+	allowedHeaders  = "Authorization, Content-Type, X-API-Key"
+	exposedHeaders  = "Retry-After"
+	preflightMaxAge = "600"
+)
+
+// Middleware applies the policy to REST requests
+// Allowed origins get CORS headers and preflight responses; other origins get 403
+func Middleware(policy *Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !policy.CheckOrigin(c.Request) {
+			slog.Warn("Request rejected: origin not allowed", "origin", origin, "method", c.Request.Method, "path", c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+		header.Set("Access-Control-Expose-Headers", exposedHeaders)
+
+		// Answer preflight requests without running the route
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", allowedMethods)
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+			header.Set("Access-Control-Max-Age", preflightMaxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Policy decides which browser origins may use the WebSocket and REST endpoints
+// Same-origin requests and requests without an Origin header are always allowed
+type Policy struct {
+	allowAll bool
+	exact    map[string]bool // "scheme://host[:port]"
+	suffixes []originPattern // "scheme://*.domain[:port]"
+}
+
+// originPattern is a wildcard subdomain entry such as https://*.example.com
+type originPattern struct {
+	scheme string
+	suffix string // ".example.com" or ".example.com:8443"
+}
+
+// NewPolicy creates a policy from allowed origin patterns
+// Patterns are exact origins ("https://app.example.com"), wildcard subdomains
+// ("https://*.example.com") or "*" to allow any origin
+func NewPolicy(patterns []string) (*Policy, error) {
+	p := &Policy{exact: make(map[string]bool)}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if pattern == "*" {
+			p.allowAll = true
+			continue
+		}
+
+		u, err := url.Parse(pattern)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return nil, fmt.Errorf("invalid allowed origin %q: must be scheme://host[:port]", pattern)
+		}
+
+		if strings.HasPrefix(u.Host, "*.") {
+			suffix := u.Host[1:]
+			if strings.Contains(suffix, "*") || len(suffix) < 2 {
+				return nil, fmt.Errorf("invalid allowed origin %q: only a leading \"*.\" wildcard is supported", pattern)
+			}
+			p.suffixes = append(p.suffixes, originPattern{scheme: u.Scheme, suffix: suffix})
+			continue
+		}
+		if strings.Contains(u.Host, "*") {
+			return nil, fmt.Errorf("invalid allowed origin %q: only a leading \"*.\" wildcard is supported", pattern)
+		}
+		p.exact[u.Scheme+"://"+u.Host] = true
+	}
+	return p, nil
+}
+
+// Allowed reports whether an Origin header value matches the policy
+func (p *Policy) Allowed(origin string) bool {
+	if p == nil {
+		return false
+	}
+	if p.allowAll {
+		return true
+	}
+
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	if p.exact[u.Scheme+"://"+u.Host] {
+		return true
+	}
+	for _, pattern := range p.suffixes {
+		if u.Scheme == pattern.scheme && strings.HasSuffix(u.Host, pattern.suffix) && len(u.Host) > len(pattern.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin reports whether a request may proceed based on its Origin header
+// It has the signature of websocket.Upgrader.CheckOrigin
+func (p *Policy) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true // Not a browser request
+	}
+	if sameOrigin(origin, r.Host) {
+		return true
+	}
+	return p.Allowed(origin)
+}
+
+// sameOrigin reports whether origin refers to the host the request was sent to
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/pubsub"
+	"github.com/tarunm/pubsub-system/internal/tenant"
+)
+
+// AdminHandler handles the admin REST API for managing API keys and connected clients
+type AdminHandler struct {
+	engine    *pubsub.PubSubEngine
+	store     *auth.KeyStore
+	writeWait time.Duration // How long a disconnected client gets to receive its queued messages
+	audit     *audit.Logger // nil when auditing is disabled
+}
+
+// NewAdminHandler creates a new admin handler
+// writeWait is the configured WebSocket write timeout
+func NewAdminHandler(engine *pubsub.PubSubEngine, store *auth.KeyStore, writeWait time.Duration) *AdminHandler {
+	return &AdminHandler{
+		engine:    engine,
+		store:     store,
+		writeWait: writeWait,
+	}
+}
+
+// SetAuditLog records key changes to logger
+func (h *AdminHandler) SetAuditLog(logger *audit.Logger) {
+	h.audit = logger
+}
+
+// CreateKey handles POST /admin/keys
+func (h *AdminHandler) CreateKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "label is required"})
+		return
+	}
+
+	if req.Tenant != "" && !tenant.ValidName(req.Tenant) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant may only contain letters, digits, '-' and '_'"})
+		return
+	}
+
+	expiresAt, err := parseExpiry(req.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be an RFC3339 timestamp"})
+		return
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	createdBy := auth.GetPrincipal(c).String()
+	key, record, err := h.store.Create(req.Label, req.Tenant, createdBy, expiresAt)
+	if err != nil {
+		slog.Error("Failed to create API key", "error", err)
+		h.audit.Record(auditEvent(c, audit.ActionKeyCreate, audit.OutcomeFailure, req.Label, err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	slog.Info("API key created", "key_id", record.ID, "label", record.Label, "principal", createdBy)
+	h.audit.Record(auditEvent(c, audit.ActionKeyCreate, audit.OutcomeSuccess, record.ID, ""))
+
+	info := keyInfo(record)
+	c.JSON(http.StatusCreated, models.CreateAPIKeyResponse{
+		ID:        info.ID,
+		Key:       key,
+		Label:     info.Label,
+		Tenant:    info.Tenant,
+		CreatedAt: info.CreatedAt,
+		ExpiresAt: info.ExpiresAt,
+	})
+}
+
+// ListKeys handles GET /admin/keys
+func (h *AdminHandler) ListKeys(c *gin.Context) {
+	records := h.store.List()
+
+	keys := make([]models.APIKeyInfo, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, keyInfo(record))
+	}
+
+	c.JSON(http.StatusOK, models.ListAPIKeysResponse{
+		Keys: keys,
+	})
+}
+
+// RevokeKey handles DELETE /admin/keys/:id
+// Clients connected with the key are disconnected immediately
+func (h *AdminHandler) RevokeKey(c *gin.Context) {
+	id := c.Param("id")
+
+	_, err := h.store.Revoke(id)
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionKeyRevoke, audit.OutcomeFailure, id, err.Error()))
+	}
+	if err == auth.ErrKeyNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	} else if err == auth.ErrKeyRevoked {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key already revoked"})
+		return
+	} else if err != nil {
+		slog.Error("Failed to revoke API key", "key_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.audit.Record(auditEvent(c, audit.ActionKeyRevoke, audit.OutcomeSuccess, id, ""))
+
+	clients := h.clientsUsingKey(id)
+	for _, sub := range clients {
+		sub.SendMessage(models.ServerMessage{
+			Type: "error",
+			Error: &models.ErrorInfo{
+				Code:    auth.ErrCodeKeyRevoked,
+				Message: auth.ErrMsgKeyRevoked,
+			},
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		})
+		sub.Disconnect(pubsub.DisconnectKeyRevoked)
+	}
+
+	slog.Info("API key revoked", "key_id", id, "disconnected_clients", len(clients))
+
+	c.JSON(http.StatusOK, models.RevokeAPIKeyResponse{
+		Status:       "revoked",
+		ID:           id,
+		Disconnected: len(clients),
+	})
+}
+
+// UpdateKey handles PATCH /admin/keys/:id
+// The new expiry also applies to clients already connected with the key
+func (h *AdminHandler) UpdateKey(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	expiresAt, err := parseExpiry(req.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be an RFC3339 timestamp"})
+		return
+	}
+
+	record, err := h.store.SetExpiry(id, expiresAt)
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionKeyUpdate, audit.OutcomeFailure, id, err.Error()))
+	}
+	if err == auth.ErrKeyNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	} else if err == auth.ErrKeyRevoked {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key is revoked"})
+		return
+	} else if err != nil {
+		slog.Error("Failed to update API key", "key_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	for _, sub := range h.clientsUsingKey(id) {
+		principal := *sub.GetPrincipal()
+		principal.ExpiresAt = time.Time{}
+		if expiresAt != nil {
+			principal.ExpiresAt = *expiresAt
+		}
+		sub.SetPrincipal(&principal)
+	}
+
+	slog.Info("API key updated", "key_id", id, "expires_at", req.ExpiresAt)
+	h.audit.Record(auditEvent(c, audit.ActionKeyUpdate, audit.OutcomeSuccess, id, ""))
+
+	c.JSON(http.StatusOK, keyInfo(record))
+}
+
+// clientsUsingKey returns connected clients authenticated with a managed key
+func (h *AdminHandler) clientsUsingKey(id string) []*pubsub.Subscriber {
+	return h.engine.FindClients(func(sub *pubsub.Subscriber) bool {
+		return sub.GetPrincipal().KeyID == id
+	})
+}
+
+// parseExpiry parses an optional RFC3339 expiry; empty means no expiry
+func parseExpiry(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// keyInfo converts a key record into its masked API representation
+func keyInfo(record auth.APIKeyRecord) models.APIKeyInfo {
+	info := models.APIKeyInfo{
+		ID:        record.ID,
+		Key:       record.Masked,
+		Label:     record.Label,
+		Tenant:    record.Tenant,
+		Status:    record.Status(),
+		CreatedAt: record.CreatedAt.UTC().Format(time.RFC3339),
+		CreatedBy: record.CreatedBy,
+	}
+	if record.ExpiresAt != nil {
+		info.ExpiresAt = record.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if record.RevokedAt != nil {
+		info.RevokedAt = record.RevokedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
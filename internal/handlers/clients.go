@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/pubsub"
+)
+
+// Page sizes for GET /admin/clients
+const (
+	defaultClientsLimit = 100
+	maxClientsLimit     = 1000
+)
+
+// Disconnect reasons must fit in a WebSocket close frame
+const (
+	defaultDisconnectReason = "Disconnected by administrator"
+	maxDisconnectReason     = 123
+)
+
+// ListClients handles GET /admin/clients
+// Clients are sorted by ID and can be filtered by tenant, topic, client IP and managed key ID
+func (h *AdminHandler) ListClients(c *gin.Context) {
+	limit, err := queryInt(c, "limit", defaultClientsLimit)
+	if err != nil || limit < 1 || limit > maxClientsLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxClientsLimit)})
+		return
+	}
+	offset, err := queryInt(c, "offset", 0)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+
+	tenantName, tenantSet := c.GetQuery("tenant")
+	topic := c.Query("topic")
+	clientIP := c.Query("client_ip")
+	keyID := c.Query("key_id")
+
+	matched := h.engine.FindClients(func(sub *pubsub.Subscriber) bool {
+		principal := sub.GetPrincipal()
+		if tenantSet && principal.Tenant != tenantName {
+			return false
+		}
+		if clientIP != "" && sub.ClientIP != clientIP {
+			return false
+		}
+		if keyID != "" && principal.KeyID != keyID {
+			return false
+		}
+		if topic != "" && !slices.ContainsFunc(sub.GetTopics(), func(key string) bool {
+			_, name := pubsub.SplitTopicKey(key)
+			return name == topic
+		}) {
+			return false
+		}
+		return true
+	})
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ClientID < matched[j].ClientID
+	})
+
+	// Clamp before adding so a huge offset cannot overflow
+	start := min(offset, len(matched))
+	page := matched[start : start+min(limit, len(matched)-start)]
+	clients := make([]models.ClientInfo, 0, len(page))
+	for _, sub := range page {
+		clients = append(clients, sub.Info())
+	}
+
+	c.JSON(http.StatusOK, models.ListClientsResponse{
+		Clients: clients,
+		Total:   len(matched),
+		Offset:  offset,
+		Limit:   limit,
+	})
+}
+
+// GetClient handles GET /admin/clients/:id
+func (h *AdminHandler) GetClient(c *gin.Context) {
+	sub, err := h.engine.GetClient(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sub.Info())
+}
+
+// DisconnectClient handles DELETE /admin/clients/:id
+// The client receives a DISCONNECTED error and messages already queued for it,
+// then the connection is closed with a policy violation close frame
+func (h *AdminHandler) DisconnectClient(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.DisconnectClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = defaultDisconnectReason
+	}
+	if len(reason) > maxDisconnectReason {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be at most " + strconv.Itoa(maxDisconnectReason) + " bytes"})
+		return
+	}
+
+	sub, err := h.engine.GetClient(id)
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionClientClose, audit.OutcomeFailure, id, err.Error()))
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+
+	sub.SendMessage(models.ServerMessage{
+		Type: "error",
+		Error: &models.ErrorInfo{
+			Code:    "DISCONNECTED",
+			Message: reason,
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+	// Audit before closing so the event precedes the client's disconnect event
+	sub.Logger().Info("Client disconnected by administrator", "reason", reason, "principal", auth.GetPrincipal(c).String())
+	h.audit.Record(auditEvent(c, audit.ActionClientClose, audit.OutcomeSuccess, id, reason))
+	sub.DisconnectWithCode(pubsub.DisconnectAdmin, websocket.ClosePolicyViolation, reason, h.writeWait)
+
+	c.JSON(http.StatusOK, models.DisconnectClientResponse{
+		Status: "disconnected",
+		ID:     id,
+	})
+}
+
+// UnsubscribeClient handles DELETE /admin/clients/:id/subscriptions/:topic
+// topic is resolved in the client's tenant; the client is notified with an "unsubscribed" info message
+func (h *AdminHandler) UnsubscribeClient(c *gin.Context) {
+	id := c.Param("id")
+	topic := c.Param("topic")
+	target := id + ":" + topic
+
+	sub, err := h.engine.GetClient(id)
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionClientUnsubscribe, audit.OutcomeFailure, target, err.Error()))
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+
+	key := pubsub.TopicKey(sub.GetPrincipal().Tenant, topic)
+	if !sub.HasTopic(key) {
+		h.audit.Record(auditEvent(c, audit.ActionClientUnsubscribe, audit.OutcomeFailure, target, "subscription not found"))
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+
+	if err := h.engine.Unsubscribe(id, key); err != nil && err != pubsub.ErrTopicNotFound {
+		slog.Error("Failed to remove subscription", "client_id", id, "topic", key, "error", err)
+		h.audit.Record(auditEvent(c, audit.ActionClientUnsubscribe, audit.OutcomeFailure, target, err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	sub.SendMessage(models.ServerMessage{
+		Type:      "info",
+		Topic:     topic,
+		Msg:       "unsubscribed",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+	h.audit.Record(auditEvent(c, audit.ActionClientUnsubscribe, audit.OutcomeSuccess, target, ""))
+
+	c.JSON(http.StatusOK, models.UnsubscribeClientResponse{
+		Status: "unsubscribed",
+		ID:     id,
+		Topic:  topic,
+	})
+}
+
+// queryInt parses an optional integer query parameter
+func queryInt(c *gin.Context, name string, fallback int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
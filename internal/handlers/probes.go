@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// Probe and check statuses
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	CheckFailed       = "fail"
+)
+
+// livenessTimeout is how long the engine lock may be held before the server is considered wedged
+const livenessTimeout = time.Second
+
+// Livez handles GET /livez
+// It fails only when the process needs restarting, not while it is busy or shutting down
+func (h *RESTHandler) Livez(c *gin.Context) {
+	respondProbe(c, h.livenessChecks())
+}
+
+// Readyz handles GET /readyz
+// It fails while the server should not be sent new clients: shutting down,
+// unable to persist state, or at its connection limit
+func (h *RESTHandler) Readyz(c *gin.Context) {
+	respondProbe(c, h.readinessChecks())
+}
+
+func (h *RESTHandler) livenessChecks() []models.HealthCheck {
+	return []models.HealthCheck{
+		healthCheck("engine_lock", h.engine.CheckLive(livenessTimeout)),
+	}
+}
+
+func (h *RESTHandler) readinessChecks() []models.HealthCheck {
+	checks := []models.HealthCheck{
+		healthCheck("engine", h.engine.CheckReady()),
+	}
+	if h.keyStore != nil {
+		checks = append(checks, healthCheck("key_store", h.keyStore.CheckWritable()))
+	}
+	return append(checks, healthCheck("connections", h.admission.CheckCapacity()))
+}
+
+// respondProbe writes checks with 200 when all pass and 503 otherwise
+func respondProbe(c *gin.Context, checks []models.HealthCheck) {
+	status := probeStatus(checks)
+	code := http.StatusOK
+	if status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, models.ProbeResponse{Status: status, Checks: checks})
+}
+
+// probeStatus returns StatusUnavailable when any check failed
+func probeStatus(checks []models.HealthCheck) string {
+	for _, check := range checks {
+		if check.Status != StatusOK {
+			return StatusUnavailable
+		}
+	}
+	return StatusOK
+}
+
+// healthCheck reports a component as failed with err as the reason, or ok when err is nil
+func healthCheck(name string, err error) models.HealthCheck {
+	if err != nil {
+		return models.HealthCheck{Name: name, Status: CheckFailed, Reason: err.Error()}
+	}
+	return models.HealthCheck{Name: name, Status: StatusOK}
+}
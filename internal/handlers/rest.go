@@ -1,17 +1,28 @@
 package handlers
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tarunm/pubsub-system/internal/admission"
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/auth"
 	"github.com/tarunm/pubsub-system/internal/models"
 	"github.com/tarunm/pubsub-system/internal/pubsub"
+	"github.com/tarunm/pubsub-system/internal/tenant"
 )
 
 // RESTHandler handles REST API endpoints
 type RESTHandler struct {
-	engine *pubsub.PubSubEngine
+	engine    *pubsub.PubSubEngine
+	acl       *auth.ACL             // nil when no ACL policy is loaded
+	tenants   *tenant.Registry      // nil when tenants are not configured
+	admission *admission.Controller // nil when connection usage is not tracked
+	keyStore  *auth.KeyStore        // nil when the admin API is disabled
+	audit     *audit.Logger         // nil when auditing is disabled
 }
 
 // NewRESTHandler creates a new REST handler
@@ -19,6 +30,31 @@ func NewRESTHandler(engine *pubsub.PubSubEngine) *RESTHandler {
 	return &RESTHandler{engine: engine}
 }
 
+// SetACL enforces a topic access control policy on topic creation and deletion
+func (h *RESTHandler) SetACL(acl *auth.ACL) {
+	h.acl = acl
+}
+
+// SetTenants enforces per-tenant topic limits
+func (h *RESTHandler) SetTenants(tenants *tenant.Registry) {
+	h.tenants = tenants
+}
+
+// SetAdmission reports connection usage from controller in /health
+func (h *RESTHandler) SetAdmission(controller *admission.Controller) {
+	h.admission = controller
+}
+
+// SetKeyStore reports whether managed keys can be persisted in /readyz
+func (h *RESTHandler) SetKeyStore(store *auth.KeyStore) {
+	h.keyStore = store
+}
+
+// SetAuditLog records topic and scheduled message changes to logger
+func (h *RESTHandler) SetAuditLog(logger *audit.Logger) {
+	h.audit = logger
+}
+
 // CreateTopic handles POST /topics
 func (h *RESTHandler) CreateTopic(c *gin.Context) {
 	var req models.CreateTopicRequest
@@ -33,21 +69,48 @@ func (h *RESTHandler) CreateTopic(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "topic name cannot be empty"})
 		return
 	}
+	if !pubsub.ValidTopicName(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("topic name cannot contain '%s'", pubsub.TenantSeparator)})
+		return
+	}
+
+	principal := auth.GetPrincipal(c)
+	if !h.acl.Authorize(principal, auth.ActionCreate, req.Name) {
+		h.audit.Record(auditEvent(c, audit.ActionTopicCreate, audit.OutcomeDenied, req.Name, auth.ErrCodeForbidden))
+		auth.AbortForbidden(c, fmt.Sprintf("Not permitted to create topic '%s'", req.Name))
+		return
+	}
+
+	partitions := req.Partitions
+	if partitions == 0 {
+		partitions = 1
+	}
 
 	// Create topic
-	err := h.engine.CreateTopic(req.Name)
+	err := h.engine.CreateTenantTopic(principal.Tenant, req.Name, partitions, h.tenants.MaxTopics(principal.Tenant))
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionTopicCreate, audit.OutcomeFailure, req.Name, err.Error()))
+	}
 	if err == pubsub.ErrTopicExists {
 		c.JSON(http.StatusConflict, gin.H{"error": "topic already exists"})
 		return
+	} else if err == pubsub.ErrTopicLimit {
+		c.JSON(http.StatusForbidden, gin.H{"error": "tenant topic limit reached"})
+		return
+	} else if err == pubsub.ErrInvalidPartitions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("partitions must be between 1 and %d", pubsub.MaxPartitions)})
+		return
 	} else if err != nil {
-		log.Printf("[ERROR] Failed to create topic: %v", err)
+		slog.Error("Failed to create topic", "topic", req.Name, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	h.audit.Record(auditEvent(c, audit.ActionTopicCreate, audit.OutcomeSuccess, req.Name, ""))
 	c.JSON(http.StatusCreated, models.CreateTopicResponse{
-		Status: "created",
-		Topic:  req.Name,
+		Status:     "created",
+		Topic:      req.Name,
+		Partitions: partitions,
 	})
 }
 
@@ -60,17 +123,28 @@ func (h *RESTHandler) DeleteTopic(c *gin.Context) {
 		return
 	}
 
+	principal := auth.GetPrincipal(c)
+	if !h.acl.Authorize(principal, auth.ActionDelete, name) {
+		h.audit.Record(auditEvent(c, audit.ActionTopicDelete, audit.OutcomeDenied, name, auth.ErrCodeForbidden))
+		auth.AbortForbidden(c, fmt.Sprintf("Not permitted to delete topic '%s'", name))
+		return
+	}
+
 	// Delete topic
-	err := h.engine.DeleteTopic(name)
+	err := h.engine.DeleteTopic(pubsub.TopicKey(principal.Tenant, name))
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionTopicDelete, audit.OutcomeFailure, name, err.Error()))
+	}
 	if err == pubsub.ErrTopicNotFound {
 		c.JSON(http.StatusNotFound, gin.H{"error": "topic not found"})
 		return
 	} else if err != nil {
-		log.Printf("[ERROR] Failed to delete topic: %v", err)
+		slog.Error("Failed to delete topic", "topic", name, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	h.audit.Record(auditEvent(c, audit.ActionTopicDelete, audit.OutcomeSuccess, name, ""))
 	c.JSON(http.StatusOK, models.DeleteTopicResponse{
 		Status: "deleted",
 		Topic:  name,
@@ -79,7 +153,7 @@ func (h *RESTHandler) DeleteTopic(c *gin.Context) {
 
 // ListTopics handles GET /topics
 func (h *RESTHandler) ListTopics(c *gin.Context) {
-	topics := h.engine.ListTopics()
+	topics := h.engine.ListTopics(auth.GetPrincipal(c).Tenant)
 
 	c.JSON(http.StatusOK, models.ListTopicsResponse{
 		Topics: topics,
@@ -87,13 +161,120 @@ func (h *RESTHandler) ListTopics(c *gin.Context) {
 }
 
 // GetHealth handles GET /health
+// It always returns 200; status and checks report what /livez and /readyz would
 func (h *RESTHandler) GetHealth(c *gin.Context) {
 	health := h.engine.GetHealth()
+	health.Checks = append(h.livenessChecks(), h.readinessChecks()...)
+	health.Status = probeStatus(health.Checks)
+	if h.admission != nil {
+		usage := h.admission.Usage()
+		health.Connections = &usage
+	}
 	c.JSON(http.StatusOK, health)
 }
 
 // GetStats handles GET /stats
 func (h *RESTHandler) GetStats(c *gin.Context) {
-	stats := h.engine.GetStats()
+	stats := h.engine.GetStats(auth.GetPrincipal(c).Tenant)
 	c.JSON(http.StatusOK, stats)
 }
+
+// GetMetrics handles GET /metrics
+// Metrics are labelled with every tenant's topics, so principals bound to a tenant are refused
+func (h *RESTHandler) GetMetrics(c *gin.Context) {
+	if auth.GetPrincipal(c).Tenant != "" {
+		auth.AbortForbidden(c, "Metrics are not available to tenant credentials")
+		return
+	}
+	h.engine.Metrics().Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// ListScheduled handles GET /scheduled
+func (h *RESTHandler) ListScheduled(c *gin.Context) {
+	topic := c.Query("topic")
+	if topic != "" && !pubsub.ValidTopicName(topic) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("topic name cannot contain '%s'", pubsub.TenantSeparator)})
+		return
+	}
+
+	// Scheduled messages are visible to principals that may publish them
+	principal := auth.GetPrincipal(c)
+	if topic != "" && !h.acl.Authorize(principal, auth.ActionPublish, topic) {
+		auth.AbortForbidden(c, fmt.Sprintf("Not permitted to publish to topic '%s'", topic))
+		return
+	}
+
+	pending := h.engine.ListScheduled(principal.Tenant, topic)
+
+	scheduled := make([]models.ScheduledMessageInfo, 0, len(pending))
+	for _, sm := range pending {
+		msg := sm.Message
+		_, topicName := pubsub.SplitTopicKey(sm.Topic)
+		if !h.acl.Authorize(principal, auth.ActionPublish, topicName) {
+			continue
+		}
+		scheduled = append(scheduled, models.ScheduledMessageInfo{
+			ID:        msg.ID,
+			Topic:     topicName,
+			Message:   &msg,
+			DeliverAt: sm.DeliverAt.UTC().Format(time.RFC3339Nano),
+			CreatedAt: sm.CreatedAt.UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	c.JSON(http.StatusOK, models.ListScheduledResponse{
+		Scheduled: scheduled,
+	})
+}
+
+// CancelScheduled handles DELETE /scheduled/:id
+func (h *RESTHandler) CancelScheduled(c *gin.Context) {
+	id := c.Param("id")
+
+	// Cancelling requires permission to publish to the message's topic
+	principal := auth.GetPrincipal(c)
+	if pending, exists := h.engine.GetScheduled(principal.Tenant, id); exists {
+		_, topicName := pubsub.SplitTopicKey(pending.Topic)
+		if !h.acl.Authorize(principal, auth.ActionPublish, topicName) {
+			h.audit.Record(auditEvent(c, audit.ActionScheduledCancel, audit.OutcomeDenied, id, auth.ErrCodeForbidden))
+			auth.AbortForbidden(c, fmt.Sprintf("Not permitted to publish to topic '%s'", topicName))
+			return
+		}
+	}
+
+	sm, err := h.engine.CancelScheduled(principal.Tenant, id)
+	if err != nil {
+		h.audit.Record(auditEvent(c, audit.ActionScheduledCancel, audit.OutcomeFailure, id, err.Error()))
+	}
+	if err == pubsub.ErrScheduledNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "scheduled message not found"})
+		return
+	} else if err != nil {
+		slog.Error("Failed to cancel scheduled message", "message_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.audit.Record(auditEvent(c, audit.ActionScheduledCancel, audit.OutcomeSuccess, id, ""))
+
+	_, topicName := pubsub.SplitTopicKey(sm.Topic)
+	c.JSON(http.StatusOK, models.CancelScheduledResponse{
+		Status: "cancelled",
+		ID:     id,
+		Topic:  topicName,
+	})
+}
+
+// auditEvent builds an audit event for a REST request made by the authenticated principal
+func auditEvent(c *gin.Context, action, outcome, target, reason string) audit.Event {
+	principal := auth.GetPrincipal(c)
+	return audit.Event{
+		Action:    action,
+		Outcome:   outcome,
+		Principal: principal.String(),
+		Tenant:    principal.Tenant,
+		Target:    target,
+		ClientIP:  c.ClientIP(),
+		Reason:    reason,
+	}
+}
@@ -1,26 +1,36 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/admission"
+	"github.com/tarunm/pubsub-system/internal/audit"
 	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/cors"
+	"github.com/tarunm/pubsub-system/internal/metrics"
 	"github.com/tarunm/pubsub-system/internal/models"
 	"github.com/tarunm/pubsub-system/internal/pubsub"
+	"github.com/tarunm/pubsub-system/internal/ratelimit"
+	"github.com/tarunm/pubsub-system/internal/tenant"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for demo purposes
-	},
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-}
+// maxOrderingKeyLength bounds the size of a message ordering key
+const maxOrderingKeyLength = 256
+
+// Limits on message headers
+const (
+	maxHeaders      = 32   // Headers per message
+	maxHeaderLength = 1024 // Bytes per header name plus value
+)
 
 // WebSocketConfig interface for handler configuration
 type WebSocketConfig interface {
@@ -28,6 +38,8 @@ type WebSocketConfig interface {
 	GetPingPeriod() time.Duration
 	GetPongWait() time.Duration
 	GetWriteWait() time.Duration
+	GetRequestTimeout() time.Duration
+	GetMaxRequestTimeout() time.Duration
 }
 
 // WebSocketHandler handles WebSocket connections
@@ -35,30 +47,115 @@ type WebSocketHandler struct {
 	engine    *pubsub.PubSubEngine
 	config    WebSocketConfig
 	validator *auth.APIKeyValidator
+	acl       *auth.ACL          // nil when no ACL policy is loaded
+	tenants   *tenant.Registry   // nil when tenants are not configured
+	limiter   *ratelimit.Limiter // nil when no rate limits are configured
+	admission *admission.Controller
+	origins   *cors.Policy  // nil allows same-origin browsers only
+	audit     *audit.Logger // nil when auditing is disabled
+	upgrader  websocket.Upgrader
+
+	// Connection metrics
+	connections       *metrics.Gauge
+	connectionsOpened *metrics.Counter
+	rejected          *metrics.CounterVec // reason
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
 func NewWebSocketHandler(engine *pubsub.PubSubEngine, config WebSocketConfig, validator *auth.APIKeyValidator) *WebSocketHandler {
-	return &WebSocketHandler{
+	registry := engine.Metrics()
+	h := &WebSocketHandler{
 		engine:    engine,
 		config:    config,
 		validator: validator,
+		connections: registry.NewGauge("pubsub_websocket_connections",
+			"Open WebSocket connections."),
+		connectionsOpened: registry.NewCounter("pubsub_websocket_connections_opened_total",
+			"WebSocket connections accepted since startup."),
+		rejected: registry.NewCounterVec("pubsub_websocket_connections_rejected_total",
+			"WebSocket upgrades rejected before the handshake, by reason.", "reason"),
+	}
+	h.upgrader = websocket.Upgrader{
+		CheckOrigin:     h.checkOrigin,
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
 	}
+	return h
+}
+
+// SetACL enforces a topic access control policy on subscribe and publish
+func (h *WebSocketHandler) SetACL(acl *auth.ACL) {
+	h.acl = acl
+}
+
+// SetTenants enforces per-tenant connection and publish rate limits
+func (h *WebSocketHandler) SetTenants(tenants *tenant.Registry) {
+	h.tenants = tenants
+}
+
+// SetRateLimiter enforces publish and connection attempt rate limits
+func (h *WebSocketHandler) SetRateLimiter(limiter *ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetAdmission enforces connection and subscription caps
+func (h *WebSocketHandler) SetAdmission(controller *admission.Controller) {
+	h.admission = controller
+}
+
+// SetOriginPolicy sets which browser origins may open WebSocket connections
+func (h *WebSocketHandler) SetOriginPolicy(policy *cors.Policy) {
+	h.origins = policy
+}
+
+// SetAuditLog records authentication attempts and forced disconnects to logger
+func (h *WebSocketHandler) SetAuditLog(logger *audit.Logger) {
+	h.audit = logger
+}
+
+// checkOrigin applies the origin policy to an upgrade request
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	return h.origins.CheckOrigin(r)
 }
 
 // HandleWebSocket handles WebSocket upgrade and connection
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Check if engine is shutting down
 	if h.engine.IsShuttingDown() {
+		h.rejected.With("shutting_down").Inc()
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server is shutting down"})
 		return
 	}
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		log.Printf("[ERROR] WebSocket upgrade error: %v", err)
+	if !h.checkOrigin(c.Request) {
+		slog.Warn("WebSocket connection rejected: origin not allowed", "origin", c.GetHeader("Origin"), "client_ip", c.ClientIP())
+		h.rejected.With("origin").Inc()
+		c.JSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+		return
+	}
+
+	// Throttle connection attempts before upgrading
+	if allowed, retryAfter := h.limiter.AllowConnect(c.ClientIP()); !allowed {
+		slog.Warn("WebSocket connection rejected: rate limit exceeded", "client_ip", c.ClientIP())
+		h.rejected.With("rate_limited").Inc()
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many connection attempts"})
+		return
+	}
+
+	// Reserve a connection slot before upgrading so rejected clients get a plain HTTP error
+	clientIP := c.ClientIP()
+	if err := h.admission.Admit(clientIP); err != nil {
+		slog.Warn("WebSocket connection rejected", "client_ip", clientIP, "error", err)
+		status, reason := http.StatusServiceUnavailable, "server_full"
+		if err == admission.ErrIPLimit {
+			status, reason = http.StatusTooManyRequests, "ip_limit"
+		}
+		h.rejected.With(reason).Inc()
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
+	defer h.admission.Release(clientIP)
 
 	// Generate or get client ID
 	clientID := c.Query("client_id")
@@ -66,6 +163,20 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		clientID = generateClientID()
 	}
 
+	// Client IDs are not scoped to a tenant, so a connected client's ID cannot be reused
+	if _, err := h.engine.GetClient(clientID); err == nil {
+		slog.Warn("WebSocket connection rejected: client ID in use", "client_id", clientID, "client_ip", clientIP)
+		h.rejected.With("client_id_in_use").Inc()
+		c.JSON(http.StatusConflict, gin.H{"error": "client_id already in use"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		slog.Error("WebSocket upgrade failed", "client_ip", clientIP, "error", err)
+		return
+	}
+
 	// Create and register subscriber with configuration
 	subscriber := pubsub.NewSubscriberWithConfig(
 		clientID,
@@ -75,9 +186,26 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		h.config.GetPongWait(),
 		h.config.GetWriteWait(),
 	)
-	h.engine.RegisterClient(subscriber)
+	subscriber.ClientIP = clientIP
+	if principal := auth.CertificatePrincipal(c.Request.TLS); principal != nil {
+		subscriber.SetPrincipal(principal)
+	}
+	if err := h.engine.RegisterClient(subscriber); err != nil {
+		subscriber.Logger().Warn("WebSocket client rejected", "error", err)
+		if err == pubsub.ErrShuttingDown {
+			// Shutdown began after the check above
+			h.closeUnregistered(conn, websocket.CloseGoingAway, "server shutting down")
+		} else {
+			// Another client took the ID after the check above
+			h.closeUnregistered(conn, websocket.ClosePolicyViolation, "client_id already in use")
+		}
+		return
+	}
+	h.connections.Inc()
+	h.connectionsOpened.Inc()
+	defer h.connections.Dec()
 
-	log.Printf("[INFO] WebSocket client connected: %s from %s", clientID, c.ClientIP())
+	subscriber.Logger().Info("WebSocket client connected", "client_ip", clientIP)
 
 	// Start write pump in goroutine
 	go subscriber.WritePump()
@@ -87,7 +215,16 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 
 	// Cleanup on disconnect
 	h.engine.UnregisterClient(clientID)
-	log.Printf("[INFO] WebSocket client disconnected: %s", clientID)
+	if reason := subscriber.CloseReason(); reason != "" {
+		h.recordAudit(subscriber, audit.ActionDisconnect, audit.OutcomeSuccess, reason)
+	}
+	subscriber.Logger().Info("WebSocket client disconnected", "reason", subscriber.CloseReason())
+}
+
+// closeUnregistered sends a close frame on a connection that was never registered, then closes it
+func (h *WebSocketHandler) closeUnregistered(conn *websocket.Conn, code int, text string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(h.config.GetWriteWait()))
+	conn.Close()
 }
 
 // readPump reads messages from the WebSocket connection
@@ -100,56 +237,52 @@ func (h *WebSocketHandler) readPump(sub *pubsub.Subscriber) {
 		return nil
 	})
 
-	// If auth is enabled, wait for auth message with timeout
-	if h.validator.IsEnabled() {
-		authTimeout := time.NewTimer(10 * time.Second)
-		defer authTimeout.Stop()
-
-		authChan := make(chan bool, 1)
-
-		go func() {
-			var msg models.ClientMessage
-			err := sub.Conn.ReadJSON(&msg)
-			if err != nil {
-				authChan <- false
-				return
-			}
+	// Clients with a verified certificate were authenticated during the TLS handshake;
+	// everyone else must send an auth message first when auth is enabled
+	authenticated := sub.GetPrincipal().Method == auth.MethodCertificate
+	authMessage := false
+	var authRequestID string
+	if h.validator.IsEnabled() && !authenticated {
+		requestID, ok := h.waitForAuth(sub)
+		if !ok {
+			return
+		}
+		authenticated, authMessage, authRequestID = true, true, requestID
+	}
 
-			if msg.Type != "auth" {
-				h.sendError(sub, msg.RequestID, auth.ErrCodeUnauthorized, "Authentication required. First message must be of type 'auth'")
-				authChan <- false
-				return
-			}
+	if authenticated {
+		// Reserve a tenant connection slot
+		tenantName := sub.GetPrincipal().Tenant
+		if err := h.tenants.AcquireConnection(tenantName); err != nil {
+			sub.Logger().Warn("Client rejected: tenant connection limit reached", "tenant", tenantName)
+			h.rejectClient(sub, authRequestID, "TENANT_LIMIT_EXCEEDED", "Tenant connection limit reached")
+			return
+		}
+		defer h.tenants.ReleaseConnection(tenantName)
 
-			if !h.validator.ValidateKey(msg.APIKey) {
-				h.sendError(sub, msg.RequestID, auth.ErrCodeInvalidAPIKey, auth.ErrMsgInvalidAPIKey)
-				authChan <- false
-				return
-			}
+		key := clientKey(sub)
+		if err := h.admission.AdmitKey(key); err != nil {
+			sub.Logger().Warn("Client rejected", "error", err)
+			h.rejectClient(sub, authRequestID, "CONNECTION_LIMIT_EXCEEDED", "Connection limit reached for this API key")
+			return
+		}
+		defer h.admission.ReleaseKey(key)
 
-			// Send success ack
+		// Only acknowledge the auth message once the connection is admitted
+		if authMessage {
 			sub.SendMessage(models.ServerMessage{
 				Type:      "ack",
-				RequestID: msg.RequestID,
+				RequestID: authRequestID,
 				Status:    "authenticated",
 				Timestamp: time.Now().UTC().Format(time.RFC3339),
 			})
-			log.Printf("[INFO] Client %s authenticated successfully", sub.ClientID)
-			authChan <- true
-		}()
-
-		select {
-		case success := <-authChan:
-			if !success {
-				log.Printf("[WARN] Client %s authentication failed", sub.ClientID)
-				return
-			}
-			// Authentication successful, proceed to main loop
-		case <-authTimeout.C:
-			h.sendError(sub, "", auth.ErrCodeUnauthorized, "Authentication timeout")
-			log.Printf("[WARN] Client %s authentication timeout", sub.ClientID)
-			return
+			sub.Logger().Info("Client authenticated", "principal", sub.GetPrincipal().String())
 		}
+
+		// Close idle connections whose credential expires while connected
+		done := make(chan struct{})
+		defer close(done)
+		go h.watchExpiry(sub, done)
 	}
 
 	// Main message loop (only reachable if authenticated)
@@ -158,13 +291,14 @@ func (h *WebSocketHandler) readPump(sub *pubsub.Subscriber) {
 		err := sub.Conn.ReadJSON(&msg)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("[ERROR] WebSocket error for client %s: %v", sub.ClientID, err)
+				sub.Logger().Error("WebSocket read error", "error", err)
 			}
 			break
 		}
 
 		// Reset read deadline on any message received (proves connection is alive)
 		sub.Conn.SetReadDeadline(time.Now().Add(h.config.GetPongWait()))
+		sub.RecordActivity()
 
 		// Reject auth messages after initial authentication
 		if msg.Type == "auth" {
@@ -172,13 +306,162 @@ func (h *WebSocketHandler) readPump(sub *pubsub.Subscriber) {
 			continue
 		}
 
-		log.Printf("[DEBUG] Received message from client %s: type=%s, topic=%s", sub.ClientID, msg.Type, msg.Topic)
+		// Credentials are only good until they expire, even on an open connection
+		if principal := sub.GetPrincipal(); principal.Expired() {
+			code, message := principal.ExpiredErrorCode()
+			h.sendError(sub, msg.RequestID, code, message)
+			sub.Logger().Warn("Credential expired, disconnecting")
+			sub.Disconnect(pubsub.DisconnectCredentialExpired)
+			break
+		}
+
+		sub.Logger().Debug("Received message", "type", msg.Type, "topic", msg.Topic, "request_id", msg.RequestID)
 		h.handleMessage(sub, msg)
 	}
 }
 
+// authResult is the outcome of a client's auth message
+type authResult struct {
+	requestID string
+	ok        bool
+}
+
+// waitForAuth waits for the client's auth message and authenticates it
+// Returns the auth message's request ID, and false when authentication fails or times out.
+// The caller acknowledges the auth message once the connection is admitted
+func (h *WebSocketHandler) waitForAuth(sub *pubsub.Subscriber) (string, bool) {
+	authTimeout := time.NewTimer(10 * time.Second)
+	defer authTimeout.Stop()
+
+	authChan := make(chan authResult, 1)
+
+	go func() {
+		var msg models.ClientMessage
+		err := sub.Conn.ReadJSON(&msg)
+		if err != nil {
+			authChan <- authResult{}
+			return
+		}
+
+		if msg.Type != "auth" {
+			h.authFailed(sub, auth.ErrCodeUnauthorized)
+			h.sendError(sub, msg.RequestID, auth.ErrCodeUnauthorized, "Authentication required. First message must be of type 'auth'")
+			authChan <- authResult{requestID: msg.RequestID}
+			return
+		}
+
+		credential := msg.APIKey
+		if credential == "" {
+			credential = msg.Token
+		}
+
+		principal, err := h.validator.Authenticate(credential)
+		if err != nil {
+			code, message := auth.AuthErrorCode(err)
+			h.authFailed(sub, code)
+			h.sendError(sub, msg.RequestID, code, message)
+			authChan <- authResult{requestID: msg.RequestID}
+			return
+		}
+		sub.SetPrincipal(principal)
+		h.recordAudit(sub, audit.ActionAuth, audit.OutcomeSuccess, "")
+		authChan <- authResult{requestID: msg.RequestID, ok: true}
+	}()
+
+	select {
+	case result := <-authChan:
+		if !result.ok {
+			sub.Logger().Warn("Authentication failed")
+		}
+		return result.requestID, result.ok
+	case <-authTimeout.C:
+		h.authFailed(sub, "timeout")
+		h.sendError(sub, "", auth.ErrCodeUnauthorized, "Authentication timeout")
+		sub.Logger().Warn("Authentication timed out")
+		return "", false
+	}
+}
+
+// rejectClient sends an error and closes the connection once the error is written
+// Reads until the write pump has closed the connection, so readPump's deferred
+// close cannot cut the error off
+func (h *WebSocketHandler) rejectClient(sub *pubsub.Subscriber, requestID, code, message string) {
+	h.sendError(sub, requestID, code, message)
+	sub.DisconnectWithCode(pubsub.DisconnectConnectionLimit, websocket.ClosePolicyViolation, message, h.config.GetWriteWait())
+
+	sub.Conn.SetReadDeadline(time.Now().Add(h.config.GetWriteWait()))
+	for {
+		if _, _, err := sub.Conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+// authFailed audits and counts a rejected WebSocket authentication attempt
+func (h *WebSocketHandler) authFailed(sub *pubsub.Subscriber, code string) {
+	h.validator.CountFailure("websocket", code)
+	h.recordAudit(sub, audit.ActionAuth, audit.OutcomeDenied, code)
+}
+
+// recordAudit records an event about a WebSocket client
+// Unauthenticated clients are recorded without a principal
+func (h *WebSocketHandler) recordAudit(sub *pubsub.Subscriber, action, outcome, reason string) {
+	event := audit.Event{
+		Action:   action,
+		Outcome:  outcome,
+		Target:   sub.ClientID,
+		ClientIP: sub.ClientIP,
+		Reason:   reason,
+	}
+	if principal := sub.GetPrincipal(); principal.Method != auth.MethodNone {
+		event.Principal = principal.String()
+		event.Tenant = principal.Tenant
+	}
+	h.audit.Record(event)
+}
+
+// watchExpiry closes the connection when the client's credential expires
+// The deadline is re-read whenever the principal changes, until done is closed
+func (h *WebSocketHandler) watchExpiry(sub *pubsub.Subscriber, done <-chan struct{}) {
+	for {
+		principal := sub.GetPrincipal()
+
+		var expired <-chan time.Time
+		var timer *time.Timer
+		if !principal.ExpiresAt.IsZero() {
+			timer = time.NewTimer(time.Until(principal.ExpiresAt))
+			expired = timer.C
+		}
+
+		select {
+		case <-expired:
+			sub.Logger().Warn("Credential expired, disconnecting")
+			code, message := principal.ExpiredErrorCode()
+			h.sendError(sub, "", code, message)
+			sub.Disconnect(pubsub.DisconnectCredentialExpired)
+			return
+		case <-sub.PrincipalChanged():
+		case <-done:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
 // handleMessage routes messages based on type
 func (h *WebSocketHandler) handleMessage(sub *pubsub.Subscriber, msg models.ClientMessage) {
+	if msg.Topic != "" && !pubsub.ValidTopicName(msg.Topic) {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", fmt.Sprintf("topic must not contain '%s'", pubsub.TenantSeparator))
+		return
+	}
+
 	switch msg.Type {
 	case "subscribe":
 		h.handleSubscribe(sub, msg)
@@ -186,6 +469,10 @@ func (h *WebSocketHandler) handleMessage(sub *pubsub.Subscriber, msg models.Clie
 		h.handleUnsubscribe(sub, msg)
 	case "publish":
 		h.handlePublish(sub, msg)
+	case "request":
+		h.handleRequest(sub, msg)
+	case "reply":
+		h.handleReply(sub, msg)
 	case "ping":
 		h.handlePing(sub, msg)
 	default:
@@ -201,11 +488,25 @@ func (h *WebSocketHandler) handleSubscribe(sub *pubsub.Subscriber, msg models.Cl
 		return
 	}
 
+	if !h.acl.Authorize(sub.GetPrincipal(), auth.ActionSubscribe, msg.Topic) {
+		h.sendError(sub, msg.RequestID, auth.ErrCodeForbidden, fmt.Sprintf("Not permitted to subscribe to topic '%s'", msg.Topic))
+		return
+	}
+
+	// Re-subscribing to a topic does not count against the limit
+	key := topicKey(sub, msg.Topic)
+	if max := h.admission.MaxSubscriptions(); max > 0 && !sub.HasTopic(key) && len(sub.GetTopics()) >= max {
+		h.sendError(sub, msg.RequestID, "SUBSCRIPTION_LIMIT_EXCEEDED", fmt.Sprintf("Clients may subscribe to at most %d topics", max))
+		return
+	}
+
 	// Subscribe to topic
-	history, err := h.engine.Subscribe(sub.ClientID, msg.Topic, msg.LastN)
+	history, err := h.engine.SubscribePartitions(sub.ClientID, key, msg.LastN, msg.Partitions)
 	if err != nil {
 		if err == pubsub.ErrTopicNotFound {
 			h.sendError(sub, msg.RequestID, "TOPIC_NOT_FOUND", fmt.Sprintf("Topic '%s' does not exist", msg.Topic))
+		} else if err == pubsub.ErrInvalidPartition {
+			h.sendError(sub, msg.RequestID, "BAD_REQUEST", fmt.Sprintf("Topic '%s' does not have the requested partitions", msg.Topic))
 		} else {
 			h.sendError(sub, msg.RequestID, "INTERNAL", err.Error())
 		}
@@ -231,7 +532,7 @@ func (h *WebSocketHandler) handleSubscribe(sub *pubsub.Subscriber, msg models.Cl
 				Timestamp: histMsg.Timestamp.UTC().Format(time.RFC3339),
 			})
 		}
-		log.Printf("[INFO] Sent %d historical messages to client %s for topic %s", len(history), sub.ClientID, msg.Topic)
+		sub.Logger().Info("Sent historical messages", "topic", msg.Topic, "count", len(history), "request_id", msg.RequestID)
 	}
 }
 
@@ -244,7 +545,7 @@ func (h *WebSocketHandler) handleUnsubscribe(sub *pubsub.Subscriber, msg models.
 	}
 
 	// Unsubscribe from topic
-	err := h.engine.Unsubscribe(sub.ClientID, msg.Topic)
+	err := h.engine.Unsubscribe(sub.ClientID, topicKey(sub, msg.Topic))
 	if err != nil {
 		if err == pubsub.ErrTopicNotFound {
 			h.sendError(sub, msg.RequestID, "TOPIC_NOT_FOUND", fmt.Sprintf("Topic '%s' does not exist", msg.Topic))
@@ -264,36 +565,86 @@ func (h *WebSocketHandler) handleUnsubscribe(sub *pubsub.Subscriber, msg models.
 	})
 }
 
+// validatePublish checks the topic and message of a publish or request
+// Returns an error description, or an empty string if the message is valid
+func validatePublish(msg models.ClientMessage) string {
+	if msg.Topic == "" {
+		return "topic is required"
+	}
+
+	if msg.Message == nil {
+		return "message is required"
+	}
+
+	if msg.Message.ID == "" {
+		return "message.id is required"
+	}
+
+	// Validate message ID is a valid UUID
+	if _, err := uuid.Parse(msg.Message.ID); err != nil {
+		return "message.id must be a valid UUID"
+	}
+
+	if len(msg.Message.Key) > maxOrderingKeyLength {
+		return fmt.Sprintf("message.key must be at most %d characters", maxOrderingKeyLength)
+	}
+
+	if len(msg.Message.Headers) > maxHeaders {
+		return fmt.Sprintf("message.headers must have at most %d entries", maxHeaders)
+	}
+	for name, value := range msg.Message.Headers {
+		if name == "" {
+			return "message.headers names must not be empty"
+		}
+		if len(name)+len(value) > maxHeaderLength {
+			return fmt.Sprintf("message.headers[%q] must be at most %d bytes", name, maxHeaderLength)
+		}
+	}
+
+	return ""
+}
+
 // handlePublish handles publish requests
 func (h *WebSocketHandler) handlePublish(sub *pubsub.Subscriber, msg models.ClientMessage) {
 	// Validate request
-	if msg.Topic == "" {
-		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "topic is required")
+	if problem := validatePublish(msg); problem != "" {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", problem)
 		return
 	}
 
-	if msg.Message == nil {
-		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "message is required")
+	if !h.acl.Authorize(sub.GetPrincipal(), auth.ActionPublish, msg.Topic) {
+		h.sendError(sub, msg.RequestID, auth.ErrCodeForbidden, fmt.Sprintf("Not permitted to publish to topic '%s'", msg.Topic))
 		return
 	}
 
-	if msg.Message.ID == "" {
-		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "message.id is required")
+	if !h.allowPublish(sub, msg) {
 		return
 	}
 
-	// Validate message ID is a valid UUID
-	if _, err := uuid.Parse(msg.Message.ID); err != nil {
-		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "message.id must be a valid UUID")
+	// Park the message if delayed delivery was requested
+	deliverAt, err := scheduledDeliveryTime(msg)
+	if err != nil {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", err.Error())
+		return
+	}
+	if !deliverAt.IsZero() {
+		h.handleScheduledPublish(sub, msg, deliverAt)
 		return
 	}
 
 	// Publish message
-	err := h.engine.Publish(msg.Topic, *msg.Message)
+	err = h.engine.Publish(topicKey(sub, msg.Topic), *msg.Message)
+	if err == pubsub.ErrDuplicateMessage {
+		h.sendDuplicateAck(sub, msg)
+		return
+	}
 	if err != nil {
-		if err == pubsub.ErrTopicNotFound {
+		switch err {
+		case pubsub.ErrTopicNotFound:
 			h.sendError(sub, msg.RequestID, "TOPIC_NOT_FOUND", fmt.Sprintf("Topic '%s' does not exist", msg.Topic))
-		} else {
+		case pubsub.ErrShuttingDown:
+			h.sendError(sub, msg.RequestID, "SHUTTING_DOWN", "Server is shutting down, reconnect and retry")
+		default:
 			h.sendError(sub, msg.RequestID, "INTERNAL", err.Error())
 		}
 		return
@@ -309,6 +660,184 @@ func (h *WebSocketHandler) handlePublish(sub *pubsub.Subscriber, msg models.Clie
 	})
 }
 
+// allowPublish applies tenant and configured publish rate limits
+// It sends a RATE_LIMITED error and returns false when a limit is exceeded
+func (h *WebSocketHandler) allowPublish(sub *pubsub.Subscriber, msg models.ClientMessage) bool {
+	principal := sub.GetPrincipal()
+	if !h.tenants.AllowPublish(principal.Tenant) {
+		h.sendError(sub, msg.RequestID, "RATE_LIMITED", "Tenant publish rate limit exceeded")
+		return false
+	}
+
+	size := 0
+	if h.limiter.CountsBytes() {
+		if payload, err := json.Marshal(msg.Message.Payload); err == nil {
+			size = len(payload)
+		}
+	}
+
+	if allowed, retryAfter := h.limiter.AllowPublish(clientKey(sub), topicKey(sub, msg.Topic), size); !allowed {
+		retryMs := (retryAfter + time.Millisecond - 1) / time.Millisecond
+		h.sendError(sub, msg.RequestID, "RATE_LIMITED", fmt.Sprintf("Publish rate limit exceeded, retry after %dms", retryMs))
+		return false
+	}
+	return true
+}
+
+// handleRequest publishes a request and waits for the first reply on a private inbox
+// The requester receives either a "reply" message or a REQUEST_TIMEOUT error
+func (h *WebSocketHandler) handleRequest(sub *pubsub.Subscriber, msg models.ClientMessage) {
+	// Validate request
+	if problem := validatePublish(msg); problem != "" {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", problem)
+		return
+	}
+
+	if !h.acl.Authorize(sub.GetPrincipal(), auth.ActionPublish, msg.Topic) {
+		h.sendError(sub, msg.RequestID, auth.ErrCodeForbidden, fmt.Sprintf("Not permitted to publish to topic '%s'", msg.Topic))
+		return
+	}
+
+	if !h.allowPublish(sub, msg) {
+		return
+	}
+
+	timeout := h.config.GetRequestTimeout()
+	if msg.TimeoutMs < 0 {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "timeout_ms must not be negative")
+		return
+	}
+	if msg.TimeoutMs > 0 {
+		timeout = time.Duration(msg.TimeoutMs) * time.Millisecond
+	}
+	if max := h.config.GetMaxRequestTimeout(); max > 0 && timeout > max {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", fmt.Sprintf("timeout_ms must be at most %d", max.Milliseconds()))
+		return
+	}
+
+	_, err := h.engine.Request(sub.ClientID, msg.RequestID, topicKey(sub, msg.Topic), *msg.Message, timeout)
+	if err == pubsub.ErrDuplicateMessage {
+		h.sendDuplicateAck(sub, msg)
+		return
+	}
+	if err != nil {
+		switch err {
+		case pubsub.ErrTopicNotFound:
+			h.sendError(sub, msg.RequestID, "TOPIC_NOT_FOUND", fmt.Sprintf("Topic '%s' does not exist", msg.Topic))
+		case pubsub.ErrNoResponders:
+			h.sendError(sub, msg.RequestID, "NO_RESPONDERS", fmt.Sprintf("Topic '%s' has no subscribers to answer the request", msg.Topic))
+		case pubsub.ErrShuttingDown:
+			h.sendError(sub, msg.RequestID, "SHUTTING_DOWN", "Server is shutting down, reconnect and retry")
+		default:
+			h.sendError(sub, msg.RequestID, "INTERNAL", err.Error())
+		}
+	}
+}
+
+// handleReply routes a responder's reply back to the waiting requester
+func (h *WebSocketHandler) handleReply(sub *pubsub.Subscriber, msg models.ClientMessage) {
+	// Validate request
+	if msg.ReplyTo == "" {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "reply_to is required")
+		return
+	}
+
+	if msg.Message == nil {
+		h.sendError(sub, msg.RequestID, "BAD_REQUEST", "message is required")
+		return
+	}
+
+	err := h.engine.Reply(msg.ReplyTo, *msg.Message)
+	if err != nil {
+		switch err {
+		case pubsub.ErrRequestNotFound, pubsub.ErrClientNotFound:
+			h.sendError(sub, msg.RequestID, "REQUEST_NOT_FOUND", "Request already answered, timed out or requester disconnected")
+		default:
+			h.sendError(sub, msg.RequestID, "INTERNAL", err.Error())
+		}
+		return
+	}
+
+	// Send acknowledgment
+	sub.SendMessage(models.ServerMessage{
+		Type:      "ack",
+		RequestID: msg.RequestID,
+		Status:    "ok",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handleScheduledPublish parks a publish request for delivery at deliverAt
+func (h *WebSocketHandler) handleScheduledPublish(sub *pubsub.Subscriber, msg models.ClientMessage, deliverAt time.Time) {
+	_, err := h.engine.Schedule(topicKey(sub, msg.Topic), *msg.Message, deliverAt)
+	if err == pubsub.ErrDuplicateMessage {
+		h.sendDuplicateAck(sub, msg)
+		return
+	}
+	if err != nil {
+		switch err {
+		case pubsub.ErrTopicNotFound:
+			h.sendError(sub, msg.RequestID, "TOPIC_NOT_FOUND", fmt.Sprintf("Topic '%s' does not exist", msg.Topic))
+		case pubsub.ErrAlreadyScheduled, pubsub.ErrScheduleTooFar:
+			h.sendError(sub, msg.RequestID, "BAD_REQUEST", err.Error())
+		case pubsub.ErrShuttingDown:
+			h.sendError(sub, msg.RequestID, "SHUTTING_DOWN", "Server is shutting down, reconnect and retry")
+		default:
+			h.sendError(sub, msg.RequestID, "INTERNAL", err.Error())
+		}
+		return
+	}
+
+	// Send acknowledgment
+	sub.SendMessage(models.ServerMessage{
+		Type:      "ack",
+		RequestID: msg.RequestID,
+		Topic:     msg.Topic,
+		Status:    "scheduled",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// sendDuplicateAck acknowledges a publish whose message ID was already accepted
+// Publishers can treat it as success; subscribers do not see the message again
+func (h *WebSocketHandler) sendDuplicateAck(sub *pubsub.Subscriber, msg models.ClientMessage) {
+	sub.SendMessage(models.ServerMessage{
+		Type:      "ack",
+		RequestID: msg.RequestID,
+		Topic:     msg.Topic,
+		Status:    "duplicate",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// scheduledDeliveryTime returns the requested delivery time for a publish,
+// or the zero time if the message should be delivered immediately
+func scheduledDeliveryTime(msg models.ClientMessage) (time.Time, error) {
+	if msg.DeliverAt != "" && msg.DelayMs != 0 {
+		return time.Time{}, fmt.Errorf("deliver_at and delay_ms are mutually exclusive")
+	}
+
+	var deliverAt time.Time
+	switch {
+	case msg.DelayMs < 0:
+		return time.Time{}, fmt.Errorf("delay_ms must not be negative")
+	case msg.DelayMs > 0:
+		deliverAt = time.Now().Add(time.Duration(msg.DelayMs) * time.Millisecond)
+	case msg.DeliverAt != "":
+		t, err := time.Parse(time.RFC3339, msg.DeliverAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("deliver_at must be an RFC3339 timestamp")
+		}
+		deliverAt = t
+	}
+
+	// Times in the past are delivered immediately
+	if !deliverAt.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return deliverAt, nil
+}
+
 // handlePing handles ping requests
 func (h *WebSocketHandler) handlePing(sub *pubsub.Subscriber, msg models.ClientMessage) {
 	sub.SendMessage(models.ServerMessage{
@@ -331,6 +860,21 @@ func (h *WebSocketHandler) sendError(sub *pubsub.Subscriber, requestID, code, me
 	})
 }
 
+// topicKey scopes a client-supplied topic name to the client's tenant
+func topicKey(sub *pubsub.Subscriber, name string) string {
+	return pubsub.TopicKey(sub.GetPrincipal().Tenant, name)
+}
+
+// clientKey identifies the credential a client connected with, for per-key limits
+// Unauthenticated clients are keyed individually rather than sharing one anonymous budget
+func clientKey(sub *pubsub.Subscriber) string {
+	principal := sub.GetPrincipal()
+	if principal.Method == auth.MethodNone {
+		return "client:" + sub.ClientID
+	}
+	return principal.Method + ":" + principal.ID
+}
+
 // generateClientID generates a unique client ID
 func generateClientID() string {
 	return fmt.Sprintf("client-%s", uuid.New().String()[:8])
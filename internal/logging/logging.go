@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Output formats
+const (
+	FormatText = "text" // key=value pairs
+	FormatJSON = "json" // One JSON object per line
+)
+
+// sampleTick is the window over which records are counted for sampling
+const sampleTick = time.Second
+
+// level is the minimum level of the logger installed by Setup, adjustable with SetLevel
+var level = new(slog.LevelVar)
+
+// Options configures the process logger
+type Options struct {
+	Level            string // debug, info, warn or error (default info)
+	Format           string // FormatText or FormatJSON (default text)
+	SampleInitial    int    // Records with the same level and message logged per second before sampling (0 = no sampling)
+	SampleThereafter int    // Beyond SampleInitial, log every Nth such record (0 = drop them)
+}
+
+// New creates a logger writing to w as configured by opts
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	minLevel, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(w, opts, minLevel)
+}
+
+// newLogger creates a logger writing records at or above minLevel to w
+func newLogger(w io.Writer, opts Options, minLevel slog.Leveler) (*slog.Logger, error) {
+	handlerOpts := &slog.HandlerOptions{Level: minLevel}
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q (expected %s or %s)", opts.Format, FormatText, FormatJSON)
+	}
+
+	if opts.SampleInitial > 0 {
+		handler = &samplingHandler{
+			Handler: handler,
+			sampler: &sampler{
+				initial:    uint64(opts.SampleInitial),
+				thereafter: uint64(opts.SampleThereafter),
+				counts:     make(map[sampleKey]uint64),
+			},
+		}
+	}
+	return slog.New(handler), nil
+}
+
+// Setup makes a logger writing to standard error the default
+// Output of the standard library log package is routed through it at info level
+func Setup(opts Options) error {
+	minLevel, err := parseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+	logger, err := newLogger(os.Stderr, opts, level)
+	if err != nil {
+		return err
+	}
+	level.Set(minLevel)
+	slog.SetDefault(logger)
+	return nil
+}
+
+// SetLevel changes the minimum level of the logger installed by Setup
+// Records already being handled are unaffected
+func SetLevel(name string) error {
+	minLevel, err := parseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(minLevel)
+	return nil
+}
+
+// parseLevel parses debug, info, warn or error ("" = info)
+func parseLevel(name string) (slog.Level, error) {
+	var minLevel slog.Level
+	if name != "" {
+		if err := minLevel.UnmarshalText([]byte(name)); err != nil {
+			return minLevel, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", name)
+		}
+	}
+	return minLevel, nil
+}
+
+// samplingHandler drops records once a message is logged too often
+// High-volume paths such as publishing log one record per message; under load
+// only the first records of each tick and every Nth one after that are kept
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler // Shared with handlers derived through WithAttrs and WithGroup
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.allow(r.Level, r.Message, r.Time) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
+
+// sampleKey identifies records counted together
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+// sampler counts records per level and message within the current tick
+type sampler struct {
+	initial    uint64
+	thereafter uint64
+	window     time.Time // Start of the current tick
+	counts     map[sampleKey]uint64
+	mu         sync.Mutex
+}
+
+// allow reports whether a record should be logged
+func (s *sampler) allow(level slog.Level, message string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.window) >= sampleTick {
+		s.window = now
+		clear(s.counts)
+	}
+
+	key := sampleKey{level: level, message: message}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
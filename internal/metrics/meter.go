@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateInterval is how often Tick must be called to update moving averages
+const RateInterval = 5 * time.Second
+
+// rateWindows are the periods moving averages are taken over, as in Unix load averages
+var rateWindows = [3]time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// Rates are moving averages in units per second over 1, 5 and 15 minutes
+type Rates struct {
+	M1, M5, M15 float64
+}
+
+// Meter counts events and the bytes they carry, with moving average rates of both
+// Mark only adds to atomic counters; Tick folds them into the averages.
+// A nil meter ignores updates
+type Meter struct {
+	parent       *Meter // Also marked with every update (nil = none)
+	count        atomic.Uint64
+	bytes        atomic.Uint64
+	pending      atomic.Uint64 // Events since the last tick
+	pendingBytes atomic.Uint64 // Bytes since the last tick
+	countEWMA    ewma
+	bytesEWMA    ewma
+	mu           sync.Mutex // Guards the averages
+}
+
+// NewMeter creates a meter whose updates are also applied to parent, if not nil
+func NewMeter(parent *Meter) *Meter {
+	return &Meter{parent: parent}
+}
+
+// Mark records n events carrying size bytes in total
+func (m *Meter) Mark(n, size uint64) {
+	for ; m != nil; m = m.parent {
+		m.count.Add(n)
+		m.bytes.Add(size)
+		m.pending.Add(n)
+		m.pendingBytes.Add(size)
+	}
+}
+
+// Tick updates the moving averages with the events marked since the last tick
+// Call every RateInterval; parents are not ticked
+func (m *Meter) Tick() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.countEWMA.update(m.pending.Swap(0))
+	m.bytesEWMA.update(m.pendingBytes.Swap(0))
+}
+
+// Count returns the number of events marked
+func (m *Meter) Count() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.count.Load()
+}
+
+// Bytes returns the number of bytes marked
+func (m *Meter) Bytes() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.bytes.Load()
+}
+
+// Rates returns the moving average event and byte rates
+func (m *Meter) Rates() (events, bytes Rates) {
+	if m == nil {
+		return Rates{}, Rates{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.countEWMA.rates(), m.bytesEWMA.rates()
+}
+
+// ewma holds exponentially weighted moving averages of a per-tick count
+type ewma struct {
+	perSecond [3]float64 // One per rate window
+	started   bool
+}
+
+// rateAlpha is the smoothing factor of each window for one RateInterval
+var rateAlpha = func() [3]float64 {
+	var alpha [3]float64
+	for i, window := range rateWindows {
+		alpha[i] = 1 - math.Exp(-RateInterval.Seconds()/window.Seconds())
+	}
+	return alpha
+}()
+
+func (e *ewma) update(n uint64) {
+	instant := float64(n) / RateInterval.Seconds()
+	for i := range e.perSecond {
+		if e.started {
+			e.perSecond[i] += rateAlpha[i] * (instant - e.perSecond[i])
+		} else {
+			// Seed the averages with the first interval instead of ramping up from zero
+			e.perSecond[i] = instant
+		}
+	}
+	e.started = true
+}
+
+func (e *ewma) rates() Rates {
+	return Rates{M1: e.perSecond[0], M5: e.perSecond[1], M15: e.perSecond[2]}
+}
@@ -0,0 +1,363 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a monotonically increasing count
+// A nil counter ignores updates
+type Counter struct {
+	value atomic.Uint64
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds n to the counter
+func (c *Counter) Add(n uint64) {
+	if c == nil {
+		return
+	}
+	c.value.Add(n)
+}
+
+// Value returns the current count
+func (c *Counter) Value() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.value.Load()
+}
+
+func (c *Counter) writeSamples(w io.Writer, name string) {
+	writeSample(w, name, "", float64(c.Value()))
+}
+
+// CounterVec is a set of counters partitioned by label values
+// A nil vector returns nil counters
+type CounterVec struct {
+	labels   []string
+	children map[string]*labeledCounter
+	mu       sync.RWMutex
+}
+
+// labeledCounter is one series of a CounterVec
+type labeledCounter struct {
+	labels  string // Rendered label pairs, e.g. `topic="orders"`
+	counter *Counter
+}
+
+// With returns the counter for the given label values, creating it on first use
+// Values are matched to the vector's label names by position
+func (v *CounterVec) With(values ...string) *Counter {
+	if v == nil {
+		return nil
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child.counter
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok := v.children[key]; ok {
+		return child.counter
+	}
+	child = &labeledCounter{labels: renderLabels(v.labels, values), counter: &Counter{}}
+	v.children[key] = child
+	return child.counter
+}
+
+// Delete removes the series for the given label values
+func (v *CounterVec) Delete(values ...string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.children, strings.Join(values, "\xff"))
+}
+
+func (v *CounterVec) writeSamples(w io.Writer, name string) {
+	v.mu.RLock()
+	children := make([]*labeledCounter, 0, len(v.children))
+	for _, child := range v.children {
+		children = append(children, child)
+	}
+	v.mu.RUnlock()
+
+	sort.Slice(children, func(i, j int) bool { return children[i].labels < children[j].labels })
+	for _, child := range children {
+		writeSample(w, name, child.labels, float64(child.counter.Value()))
+	}
+}
+
+// Gauge is a value that can go up and down
+// A nil gauge ignores updates
+type Gauge struct {
+	value atomic.Int64
+}
+
+// Inc adds one to the gauge
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+// Dec subtracts one from the gauge
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+// Add adds n to the gauge
+func (g *Gauge) Add(n int64) {
+	if g == nil {
+		return
+	}
+	g.value.Add(n)
+}
+
+// Value returns the current value
+func (g *Gauge) Value() int64 {
+	if g == nil {
+		return 0
+	}
+	return g.value.Load()
+}
+
+func (g *Gauge) writeSamples(w io.Writer, name string) {
+	writeSample(w, name, "", float64(g.Value()))
+}
+
+// gaugeFunc is a gauge whose value is computed at scrape time
+type gaugeFunc func() float64
+
+func (f gaugeFunc) writeSamples(w io.Writer, name string) {
+	writeSample(w, name, "", f())
+}
+
+// Histogram counts observations in cumulative buckets
+// A nil histogram ignores observations
+type Histogram struct {
+	bounds  []float64       // Upper bounds, ascending
+	buckets []atomic.Uint64 // Per-bucket (non-cumulative) counts; the last is +Inf
+	count   atomic.Uint64
+	sumBits atomic.Uint64 // math.Float64bits of the sum of observations
+}
+
+// Observe records a value
+func (h *Histogram) Observe(value float64) {
+	if h == nil {
+		return
+	}
+
+	i := sort.SearchFloat64s(h.bounds, value)
+	h.buckets[i].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sumBits.Load()
+		sum := math.Float64frombits(old) + value
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(sum)) {
+			return
+		}
+	}
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	if h == nil {
+		return 0
+	}
+	return h.count.Load()
+}
+
+// Quantile estimates the q-quantile (0-1) of the observations, interpolating
+// linearly within the bucket that holds it as Prometheus' histogram_quantile does
+// Returns 0 without observations; the +Inf bucket is reported as the highest bound
+func (h *Histogram) Quantile(q float64) float64 {
+	if h == nil {
+		return 0
+	}
+
+	counts := make([]uint64, len(h.buckets))
+	var total uint64
+	for i := range h.buckets {
+		counts[i] = h.buckets[i].Load()
+		total += counts[i]
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var cumulative uint64
+	for i, count := range counts {
+		if float64(cumulative+count) < rank || count == 0 {
+			cumulative += count
+			continue
+		}
+		if i == len(h.bounds) {
+			break
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = h.bounds[i-1]
+		}
+		return lower + (h.bounds[i]-lower)*(rank-float64(cumulative))/float64(count)
+	}
+	return h.bounds[len(h.bounds)-1]
+}
+
+func (h *Histogram) writeSamples(w io.Writer, name string) {
+	h.writeLabeledSamples(w, name, "")
+}
+
+// writeLabeledSamples writes the histogram's series with labels added to each
+func (h *Histogram) writeLabeledSamples(w io.Writer, name, labels string) {
+	prefix := labels
+	if prefix != "" {
+		prefix += ","
+	}
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i].Load()
+		writeSample(w, name+"_bucket", prefix+`le="`+formatFloat(bound)+`"`, float64(cumulative))
+	}
+	cumulative += h.buckets[len(h.bounds)].Load()
+	writeSample(w, name+"_bucket", prefix+`le="+Inf"`, float64(cumulative))
+	writeSample(w, name+"_sum", labels, math.Float64frombits(h.sumBits.Load()))
+	writeSample(w, name+"_count", labels, float64(h.count.Load()))
+}
+
+// HistogramVec is a set of histograms with the same buckets partitioned by label values
+// A nil vector returns nil histograms
+type HistogramVec struct {
+	labels   []string
+	bounds   []float64
+	children map[string]*labeledHistogram
+	mu       sync.RWMutex
+}
+
+// labeledHistogram is one series of a HistogramVec
+type labeledHistogram struct {
+	labels    string
+	histogram *Histogram
+}
+
+// With returns the histogram for the given label values, creating it on first use
+func (v *HistogramVec) With(values ...string) *Histogram {
+	if v == nil {
+		return nil
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child.histogram
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok := v.children[key]; ok {
+		return child.histogram
+	}
+	child = &labeledHistogram{labels: renderLabels(v.labels, values), histogram: newHistogram(v.bounds)}
+	v.children[key] = child
+	return child.histogram
+}
+
+// Delete removes the series for the given label values
+func (v *HistogramVec) Delete(values ...string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.children, strings.Join(values, "\xff"))
+}
+
+func (v *HistogramVec) writeSamples(w io.Writer, name string) {
+	v.mu.RLock()
+	children := make([]*labeledHistogram, 0, len(v.children))
+	for _, child := range v.children {
+		children = append(children, child)
+	}
+	v.mu.RUnlock()
+
+	sort.Slice(children, func(i, j int) bool { return children[i].labels < children[j].labels })
+	for _, child := range children {
+		child.histogram.writeLabeledSamples(w, name, child.labels)
+	}
+}
+
+// newHistogram creates a histogram with the given ascending bucket bounds
+func newHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds:  bounds,
+		buckets: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// ExponentialBuckets returns count bucket bounds starting at start, each factor times the previous
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+// writeSample writes one line of the text exposition format
+func writeSample(w io.Writer, name, labels string, value float64) {
+	if labels != "" {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, labels, formatFloat(value))
+	} else {
+		fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+	}
+}
+
+// renderLabels formats label pairs as name="value",... with values escaped
+func renderLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = name + `="` + labelEscaper.Replace(value) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// formatFloat formats a sample value the way Prometheus expects
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprint(v)
+}
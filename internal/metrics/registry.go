@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ContentType is the Prometheus text exposition format served by Handler
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var validName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// collector writes the samples of one metric family
+type collector interface {
+	writeSamples(w io.Writer, name string)
+}
+
+// family is a registered metric with its metadata
+type family struct {
+	name      string
+	help      string
+	kind      string // counter, gauge or histogram
+	collector collector
+}
+
+// Registry holds metrics and renders them in the Prometheus text format
+type Registry struct {
+	families []family
+	names    map[string]bool
+	mu       sync.Mutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// NewCounter registers and returns a counter
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.register(name, help, "counter", c)
+	return c
+}
+
+// NewCounterVec registers and returns a counter partitioned by labels
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{labels: labels, children: make(map[string]*labeledCounter)}
+	r.register(name, help, "counter", v)
+	return v
+}
+
+// NewGauge registers and returns a gauge
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	r.register(name, help, "gauge", g)
+	return g
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn at scrape time
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, "gauge", gaugeFunc(fn))
+}
+
+// NewHistogram registers and returns a histogram with the given ascending bucket bounds
+func (r *Registry) NewHistogram(name, help string, bounds []float64) *Histogram {
+	if !sort.Float64sAreSorted(bounds) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not sorted", name))
+	}
+	h := newHistogram(bounds)
+	r.register(name, help, "histogram", h)
+	return h
+}
+
+// NewHistogramVec registers a histogram partitioned by the given labels
+func (r *Registry) NewHistogramVec(name, help string, bounds []float64, labels ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(bounds) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not sorted", name))
+	}
+	v := &HistogramVec{labels: labels, bounds: bounds, children: make(map[string]*labeledHistogram)}
+	r.register(name, help, "histogram", v)
+	return v
+}
+
+// register adds a metric family, panicking on invalid or duplicate names
+// Metrics are registered at startup, so a panic indicates a programming error
+func (r *Registry) register(name, help, kind string, c collector) {
+	if !validName.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.names[name] = true
+	r.families = append(r.families, family{name: name, help: help, kind: kind, collector: c})
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]family, len(r.families))
+	copy(families, r.families)
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	buf := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(buf, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+		fmt.Fprintf(buf, "# TYPE %s %s\n", f.name, f.kind)
+		f.collector.writeSamples(buf, f.name)
+	}
+	return buf.Flush()
+}
+
+// Handler serves the registry's metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteText(w)
+	})
+}
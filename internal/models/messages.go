@@ -4,25 +4,36 @@ import "time"
 
 // Message represents a published message
 type Message struct {
-	ID        string      `json:"id"`
-	Payload   interface{} `json:"payload"`
-	Timestamp time.Time   `json:"-"`
+	ID        string            `json:"id"`
+	Key       string            `json:"key,omitempty"` // Ordering key; messages with the same key are delivered in order
+	Payload   interface{}       `json:"payload"`
+	Partition int               `json:"partition"`          // Assigned by the server on publish
+	Offset    int64             `json:"offset"`             // Position within the partition, assigned by the server
+	ReplyTo   string            `json:"reply_to,omitempty"` // Reply inbox for request messages
+	Headers   map[string]string `json:"headers,omitempty"`  // Application metadata, including the W3C traceparent
+	Timestamp time.Time         `json:"-"`
 }
 
 // ClientMessage represents messages from client to server
 type ClientMessage struct {
-	Type      string   `json:"type"` // subscribe, unsubscribe, publish, ping, auth
-	Topic     string   `json:"topic,omitempty"`
-	Message   *Message `json:"message,omitempty"`
-	ClientID  string   `json:"client_id,omitempty"`
-	LastN     int      `json:"last_n,omitempty"`
-	RequestID string   `json:"request_id,omitempty"`
-	APIKey    string   `json:"api_key,omitempty"` // For authentication
+	Type       string   `json:"type"` // subscribe, unsubscribe, publish, request, reply, ping, auth
+	Topic      string   `json:"topic,omitempty"`
+	Message    *Message `json:"message,omitempty"`
+	ClientID   string   `json:"client_id,omitempty"`
+	LastN      int      `json:"last_n,omitempty"`
+	RequestID  string   `json:"request_id,omitempty"`
+	APIKey     string   `json:"api_key,omitempty"`    // For authentication
+	Token      string   `json:"token,omitempty"`      // JWT bearer token, alternative to api_key
+	DeliverAt  string   `json:"deliver_at,omitempty"` // RFC3339 time for scheduled delivery
+	DelayMs    int64    `json:"delay_ms,omitempty"`   // Delay before delivery in milliseconds
+	Partitions []int    `json:"partitions,omitempty"` // Partitions to subscribe to (default: all)
+	TimeoutMs  int64    `json:"timeout_ms,omitempty"` // How long a request waits for a reply
+	ReplyTo    string   `json:"reply_to,omitempty"`   // Inbox a reply is addressed to
 }
 
 // ServerMessage represents messages from server to client
 type ServerMessage struct {
-	Type      string     `json:"type"` // ack, event, error, pong, info
+	Type      string     `json:"type"` // ack, event, reply, error, pong, info
 	RequestID string     `json:"request_id,omitempty"`
 	Topic     string     `json:"topic,omitempty"`
 	Message   *Message   `json:"message,omitempty"`
@@ -30,6 +41,11 @@ type ServerMessage struct {
 	Status    string     `json:"status,omitempty"`
 	Msg       string     `json:"msg,omitempty"`
 	Timestamp string     `json:"ts"`
+	// Set on events when delivery timestamps are enabled
+	PublishedAt string `json:"published_at,omitempty"`
+	DeliveredAt string `json:"delivered_at,omitempty"`
+	// Set on shutdown notices: how long to wait before reconnecting
+	ReconnectAfterMs int64 `json:"reconnect_after_ms,omitempty"`
 }
 
 // ErrorInfo represents error details
@@ -42,35 +58,117 @@ type ErrorInfo struct {
 type TopicInfo struct {
 	Name        string `json:"name"`
 	Subscribers int    `json:"subscribers"`
+	Partitions  int    `json:"partitions"`
 }
 
 // TopicStats represents topic statistics
 type TopicStats struct {
-	Messages    int64 `json:"messages"`
-	Subscribers int   `json:"subscribers"`
+	Messages      int64               `json:"messages"`
+	Subscribers   int                 `json:"subscribers"`
+	DedupHits     int64               `json:"dedup_hits"` // Duplicate publishes suppressed
+	Partitions    []PartitionStats    `json:"partitions"`
+	Subscriptions []SubscriptionStats `json:"subscriptions"` // Sorted by client ID
+	Throughput    ThroughputStats     `json:"throughput"`
+	Latency       LatencyStats        `json:"latency"`
+}
+
+// LatencyStats represents percentiles of the time from publish until an event is written to a subscriber
+// Counted since the topic was created; replayed history is not included
+type LatencyStats struct {
+	Count uint64  `json:"count"`  // Events timed
+	P50   float64 `json:"p50_ms"` // Milliseconds
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+}
+
+// Rates are moving averages per second over the last 1, 5 and 15 minutes
+type Rates struct {
+	M1  float64 `json:"1m"`
+	M5  float64 `json:"5m"`
+	M15 float64 `json:"15m"`
+}
+
+// ThroughputStats represents message and payload byte rates
+// Messages in are publishes; messages out are copies queued for subscribers
+type ThroughputStats struct {
+	PublishRate  Rates  `json:"publish_rate"`  // Messages per second
+	DeliveryRate Rates  `json:"delivery_rate"` // Messages per second
+	BytesIn      uint64 `json:"bytes_in"`      // Payload bytes published
+	BytesOut     uint64 `json:"bytes_out"`     // Payload bytes queued for subscribers
+	BytesInRate  Rates  `json:"bytes_in_rate"` // Bytes per second
+	BytesOutRate Rates  `json:"bytes_out_rate"`
+}
+
+// PartitionStats represents statistics for a single topic partition
+type PartitionStats struct {
+	ID       int   `json:"id"`
+	Offset   int64 `json:"offset"`   // Offset the next message will receive
+	Buffered int   `json:"buffered"` // Messages held in history for replay
+}
+
+// SubscriptionStats represents delivery statistics for one subscriber of a topic
+type SubscriptionStats struct {
+	ClientID     string `json:"client_id"`
+	Delivered    int64  `json:"delivered"`   // Events written to the client
+	Dropped      int64  `json:"dropped"`     // Events discarded because the client's queue was full
+	QueueDepth   int    `json:"queue_depth"` // Events of this topic queued but not yet written to the client
+	Lag          int64  `json:"lag"`         // Messages published since subscribing but not yet written to the client
+	LastDelivery string `json:"last_delivery,omitempty"`
 }
 
 // StatsResponse represents the /stats endpoint response
 type StatsResponse struct {
-	Topics map[string]TopicStats `json:"topics"`
+	Topics     map[string]TopicStats `json:"topics"`
+	Throughput ThroughputStats       `json:"throughput"` // Sum over the topics
 }
 
 // HealthResponse represents the /health endpoint response
 type HealthResponse struct {
-	UptimeSec   int `json:"uptime_sec"`
-	Topics      int `json:"topics"`
-	Subscribers int `json:"subscribers"`
+	Status      string           `json:"status"` // ok, or unavailable when a check fails
+	Checks      []HealthCheck    `json:"checks"`
+	UptimeSec   int              `json:"uptime_sec"`
+	Topics      int              `json:"topics"`
+	Subscribers int              `json:"subscribers"`
+	Throughput  ThroughputStats  `json:"throughput"` // All tenants, including deleted topics
+	Connections *ConnectionUsage `json:"connections,omitempty"`
+}
+
+// HealthCheck represents the state of one component
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`           // ok or fail
+	Reason string `json:"reason,omitempty"` // Why the check failed
+}
+
+// ProbeResponse represents the /livez and /readyz endpoint responses
+type ProbeResponse struct {
+	Status string        `json:"status"` // ok, or unavailable when a check fails
+	Checks []HealthCheck `json:"checks"`
+}
+
+// ConnectionUsage reports open WebSocket connections against the configured caps
+// Limits of 0 mean unlimited
+type ConnectionUsage struct {
+	Active                    int `json:"active"`
+	ClientIPs                 int `json:"client_ips"` // Distinct IPs with open connections
+	APIKeys                   int `json:"api_keys"`   // Distinct credentials with open connections
+	MaxConnections            int `json:"max_connections"`
+	MaxConnectionsPerKey      int `json:"max_connections_per_key"`
+	MaxConnectionsPerIP       int `json:"max_connections_per_ip"`
+	MaxSubscriptionsPerClient int `json:"max_subscriptions_per_client"`
 }
 
 // CreateTopicRequest represents the request body for creating a topic
 type CreateTopicRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	Partitions int    `json:"partitions,omitempty"` // Number of partitions (default: 1)
 }
 
 // CreateTopicResponse represents the response for creating a topic
 type CreateTopicResponse struct {
-	Status string `json:"status"`
-	Topic  string `json:"topic"`
+	Status     string `json:"status"`
+	Topic      string `json:"topic"`
+	Partitions int    `json:"partitions"`
 }
 
 // DeleteTopicResponse represents the response for deleting a topic
@@ -83,3 +181,115 @@ type DeleteTopicResponse struct {
 type ListTopicsResponse struct {
 	Topics []TopicInfo `json:"topics"`
 }
+
+// ScheduledMessageInfo represents a message waiting for scheduled delivery
+type ScheduledMessageInfo struct {
+	ID        string   `json:"id"`
+	Topic     string   `json:"topic"`
+	Message   *Message `json:"message"`
+	DeliverAt string   `json:"deliver_at"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// ListScheduledResponse represents the response for listing scheduled messages
+type ListScheduledResponse struct {
+	Scheduled []ScheduledMessageInfo `json:"scheduled"`
+}
+
+// CancelScheduledResponse represents the response for cancelling a scheduled message
+type CancelScheduledResponse struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+	Topic  string `json:"topic"`
+}
+
+// ClientInfo describes a connected WebSocket client
+type ClientInfo struct {
+	ID              string   `json:"id"`
+	RemoteAddr      string   `json:"remote_addr"` // Address of the TCP peer (a proxy, when behind one)
+	ClientIP        string   `json:"client_ip"`
+	Principal       string   `json:"principal"` // Masked; "none:anonymous" before authentication
+	Tenant          string   `json:"tenant,omitempty"`
+	ConnectedAt     string   `json:"connected_at"`
+	LastActivity    string   `json:"last_activity"` // Last frame read from or written to the client
+	Topics          []string `json:"topics"`
+	QueueDepth      int      `json:"queue_depth"` // Messages waiting to be written
+	QueueSize       int      `json:"queue_size"`
+	MessagesSent    int64    `json:"messages_sent"`
+	MessagesDropped int64    `json:"messages_dropped"` // Discarded because the queue was full
+}
+
+// ListClientsResponse represents the response for listing connected clients
+// Total counts every client matching the filters, not just the returned page
+type ListClientsResponse struct {
+	Clients []ClientInfo `json:"clients"`
+	Total   int          `json:"total"`
+	Offset  int          `json:"offset"`
+	Limit   int          `json:"limit"`
+}
+
+// DisconnectClientRequest represents the optional request body for disconnecting a client
+type DisconnectClientRequest struct {
+	Reason string `json:"reason,omitempty"` // Sent to the client; empty = "Disconnected by administrator"
+}
+
+// DisconnectClientResponse represents the response for disconnecting a client
+type DisconnectClientResponse struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+// UnsubscribeClientResponse represents the response for removing a client's subscription
+type UnsubscribeClientResponse struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+	Topic  string `json:"topic"`
+}
+
+// CreateAPIKeyRequest represents the request body for creating a managed API key
+type CreateAPIKeyRequest struct {
+	Label     string `json:"label" binding:"required"`
+	Tenant    string `json:"tenant,omitempty"`     // Empty = default tenant
+	ExpiresAt string `json:"expires_at,omitempty"` // RFC3339; empty = never expires
+}
+
+// UpdateAPIKeyRequest represents the request body for changing a key's expiry
+type UpdateAPIKeyRequest struct {
+	ExpiresAt string `json:"expires_at"` // RFC3339; empty = never expires
+}
+
+// APIKeyInfo describes a managed API key without revealing it
+type APIKeyInfo struct {
+	ID        string `json:"id"`
+	Key       string `json:"key"` // Masked
+	Label     string `json:"label"`
+	Tenant    string `json:"tenant,omitempty"`
+	Status    string `json:"status"` // "active", "expired" or "revoked"
+	CreatedAt string `json:"created_at"`
+	CreatedBy string `json:"created_by"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	RevokedAt string `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse represents the response for creating a managed API key
+// Key holds the plaintext key, which is only ever returned here
+type CreateAPIKeyResponse struct {
+	ID        string `json:"id"`
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Tenant    string `json:"tenant,omitempty"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// ListAPIKeysResponse represents the response for listing managed API keys
+type ListAPIKeysResponse struct {
+	Keys []APIKeyInfo `json:"keys"`
+}
+
+// RevokeAPIKeyResponse represents the response for revoking a managed API key
+type RevokeAPIKeyResponse struct {
+	Status       string `json:"status"`
+	ID           string `json:"id"`
+	Disconnected int    `json:"disconnected"`
+}
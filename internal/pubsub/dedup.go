@@ -0,0 +1,87 @@
+package pubsub
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupEntry records when a message ID was first seen
+type dedupEntry struct {
+	id     string
+	seenAt time.Time
+}
+
+// DedupWindow remembers recently published message IDs so publisher retries
+// can be suppressed. The window is bounded by both age and entry count.
+type DedupWindow struct {
+	seen    map[string]time.Time
+	order   []dedupEntry // Insertion order, oldest first
+	ttl     time.Duration
+	maxSize int
+	hits    int64
+	mu      sync.Mutex
+}
+
+// NewDedupWindow creates a dedup window holding at most maxSize IDs for up to ttl
+// A ttl of 0 bounds the window by count only
+func NewDedupWindow(ttl time.Duration, maxSize int) *DedupWindow {
+	return &DedupWindow{
+		seen:    make(map[string]time.Time),
+		order:   make([]dedupEntry, 0, maxSize),
+		ttl:     ttl,
+		maxSize: maxSize,
+	}
+}
+
+// IsDuplicate reports whether id was already seen within the window
+// IDs that are not duplicates are recorded as seen
+func (d *DedupWindow) IsDuplicate(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.evict(now)
+
+	if _, exists := d.seen[id]; exists {
+		d.hits++
+		return true
+	}
+
+	d.seen[id] = now
+	d.order = append(d.order, dedupEntry{id: id, seenAt: now})
+	d.evict(now)
+	return false
+}
+
+// Forget removes id from the window so it may be published again
+// Used when a recorded message is not accepted after all, or is withdrawn
+func (d *DedupWindow) Forget(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, id)
+}
+
+// Hits returns the number of duplicates suppressed
+func (d *DedupWindow) Hits() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.hits
+}
+
+// evict drops entries that are expired or exceed the count bound
+// Must be called with the lock held
+func (d *DedupWindow) evict(now time.Time) {
+	for len(d.order) > 0 {
+		oldest := d.order[0]
+		expired := d.ttl > 0 && now.Sub(oldest.seenAt) > d.ttl
+		if !expired && len(d.order) <= d.maxSize {
+			break
+		}
+
+		d.order = d.order[1:]
+		// A forgotten ID may have been recorded again since; keep the newer entry
+		if seenAt, exists := d.seen[oldest.id]; exists && seenAt.Equal(oldest.seenAt) {
+			delete(d.seen, oldest.id)
+		}
+	}
+}
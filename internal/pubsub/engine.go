@@ -1,12 +1,19 @@
 package pubsub
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/metrics"
 	"github.com/tarunm/pubsub-system/internal/models"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -21,6 +28,24 @@ var (
 
 	// ErrClientNotFound is returned when a client is not found
 	ErrClientNotFound = errors.New("client not found")
+
+	// ErrInvalidPartitions is returned when a topic is created with an invalid partition count
+	ErrInvalidPartitions = errors.New("invalid partition count")
+
+	// ErrInvalidPartition is returned when a subscription references a partition that does not exist
+	ErrInvalidPartition = errors.New("invalid partition")
+
+	// ErrDuplicateMessage is returned when a message ID was already published within the dedup window
+	ErrDuplicateMessage = errors.New("duplicate message")
+
+	// ErrScheduleTooFar is returned when a delivery time exceeds the maximum schedule delay
+	ErrScheduleTooFar = errors.New("delivery time exceeds maximum schedule delay")
+
+	// ErrShuttingDown is returned when the engine no longer accepts new work
+	ErrShuttingDown = errors.New("shutting down")
+
+	// ErrClientIDInUse is returned when a client registers with the ID of a connected client
+	ErrClientIDInUse = errors.New("client ID already in use")
 )
 
 // PubSubEngine is the core pub/sub engine managing topics and clients
@@ -29,41 +54,113 @@ type PubSubEngine struct {
 	Clients        map[string]*Subscriber
 	mu             sync.RWMutex
 	shutdown       chan struct{}
+	shutdownOnce   sync.Once
 	startTime      time.Time
 	ringBufferSize int // Configuration for ring buffer size
+	scheduler      *Scheduler
+	maxDelay       time.Duration              // Maximum schedule delay (0 = unlimited)
+	dedupWindow    time.Duration              // How long message IDs are remembered per topic
+	dedupSize      int                        // Max message IDs remembered per topic (0 = disabled)
+	stampDelivery  bool                       // Stamp publish and delivery times on events written to clients
+	drainTimeout   time.Duration              // How long Shutdown waits for clients to receive their queued events
+	requests       map[string]*pendingRequest // Pending request/reply exchanges keyed by inbox
+	requestsMu     sync.Mutex
+	registry       *metrics.Registry
+	metrics        *engineMetrics
+	tracer         trace.Tracer
+	publishes      *metrics.Meter // Engine-wide throughput, parent of every topic's meters
+	deliveries     *metrics.Meter
 }
 
 // Config interface for extracting configuration values
 type Config interface {
 	GetRingBufferSize() int
+	GetMaxScheduledMessages() int
+	GetMaxScheduleDelay() time.Duration
+	GetDedupWindow() time.Duration
+	GetDedupWindowSize() int
+	GetDeliveryTimestamps() bool
+	GetShutdownTimeout() time.Duration
 }
 
 // NewPubSubEngine creates a new pub/sub engine with configuration
 func NewPubSubEngine(cfg Config) *PubSubEngine {
 	ringBufferSize := cfg.GetRingBufferSize()
 
-	return &PubSubEngine{
+	e := &PubSubEngine{
 		Topics:         make(map[string]*Topic),
 		Clients:        make(map[string]*Subscriber),
 		shutdown:       make(chan struct{}),
 		startTime:      time.Now(),
 		ringBufferSize: ringBufferSize,
+		maxDelay:       cfg.GetMaxScheduleDelay(),
+		dedupWindow:    cfg.GetDedupWindow(),
+		dedupSize:      cfg.GetDedupWindowSize(),
+		stampDelivery:  cfg.GetDeliveryTimestamps(),
+		drainTimeout:   cfg.GetShutdownTimeout(),
+		requests:       make(map[string]*pendingRequest),
+		registry:       metrics.NewRegistry(),
+		tracer:         noopTracer,
+		publishes:      metrics.NewMeter(nil),
+		deliveries:     metrics.NewMeter(nil),
 	}
+
+	e.scheduler = NewScheduler(cfg.GetMaxScheduledMessages(), e.deliverScheduled)
+	e.scheduler.Start()
+	e.metrics = newEngineMetrics(e.registry, e)
+	go e.tickRates()
+
+	return e
 }
 
 // Topic Management
 
-// CreateTopic creates a new topic
+// CreateTopic creates a new single-partition topic
 func (e *PubSubEngine) CreateTopic(name string) error {
+	return e.CreateTopicWithPartitions(name, 1)
+}
+
+// CreateTopicWithPartitions creates a new topic split into the given number of partitions
+func (e *PubSubEngine) CreateTopicWithPartitions(name string, partitions int) error {
+	return e.CreateTenantTopic("", name, partitions, 0)
+}
+
+// CreateTenantTopic creates a topic in a tenant's namespace
+// maxTopics caps how many topics the tenant may have (0 = unlimited)
+func (e *PubSubEngine) CreateTenantTopic(tenant, name string, partitions, maxTopics int) error {
+	if partitions < 1 || partitions > MaxPartitions {
+		return ErrInvalidPartitions
+	}
+
+	key := TopicKey(tenant, name)
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if _, exists := e.Topics[name]; exists {
+	if _, exists := e.Topics[key]; exists {
 		return ErrTopicExists
 	}
 
-	e.Topics[name] = NewTopicWithBufferSize(name, e.ringBufferSize)
-	log.Printf("[INFO] Topic created: %s (buffer size: %d)", name, e.ringBufferSize)
+	if maxTopics > 0 {
+		count := 0
+		for _, topic := range e.Topics {
+			if topic.Tenant == tenant {
+				count++
+			}
+		}
+		if count >= maxTopics {
+			return ErrTopicLimit
+		}
+	}
+
+	topic := NewTopicWithConfig(name, partitions, e.ringBufferSize, e.dedupWindow, e.dedupSize)
+	topic.Tenant = tenant
+	e.metrics.instrumentTopic(topic, key)
+	topic.tracer = e.tracer
+	topic.publishes = metrics.NewMeter(e.publishes)
+	topic.deliveries = metrics.NewMeter(e.deliveries)
+	e.Topics[key] = topic
+	slog.Info("Topic created", "topic", key, "partitions", partitions, "buffer_size", e.ringBufferSize)
 	return nil
 }
 
@@ -78,14 +175,20 @@ func (e *PubSubEngine) DeleteTopic(name string) error {
 
 	delete(e.Topics, name)
 	e.mu.Unlock()
+	e.metrics.removeTopic(name)
+
+	slog.Info("Topic deleted", "topic", name)
 
-	log.Printf("[INFO] Topic deleted: %s", name)
+	// Drop messages still waiting for delivery to this topic
+	if dropped := e.scheduler.RemoveTopic(name); dropped > 0 {
+		slog.Info("Dropped scheduled messages for deleted topic", "topic", name, "count", dropped)
+	}
 
 	// Notify all subscribers
 	subscribers := topic.GetSubscribers()
 	notification := models.ServerMessage{
 		Type:      "info",
-		Topic:     name,
+		Topic:     topic.Name,
 		Msg:       "topic_deleted",
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
@@ -110,16 +213,20 @@ func (e *PubSubEngine) GetTopic(name string) (*Topic, error) {
 	return topic, nil
 }
 
-// ListTopics returns a list of all topics with their subscriber counts
-func (e *PubSubEngine) ListTopics() []models.TopicInfo {
+// ListTopics returns a tenant's topics with their subscriber counts
+func (e *PubSubEngine) ListTopics(tenant string) []models.TopicInfo {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	topics := make([]models.TopicInfo, 0, len(e.Topics))
 	for _, topic := range e.Topics {
+		if topic.Tenant != tenant {
+			continue
+		}
 		topics = append(topics, models.TopicInfo{
 			Name:        topic.Name,
 			Subscribers: topic.GetSubscriberCount(),
+			Partitions:  topic.GetPartitionCount(),
 		})
 	}
 	return topics
@@ -137,11 +244,23 @@ func (e *PubSubEngine) TopicExists(name string) bool {
 
 // Subscribe subscribes a client to a topic and returns historical messages if requested
 func (e *PubSubEngine) Subscribe(clientID, topicName string, lastN int) ([]models.Message, error) {
+	return e.SubscribePartitions(clientID, topicName, lastN, nil)
+}
+
+// SubscribePartitions subscribes a client to selected partitions of a topic
+// An empty partition list subscribes to all partitions
+func (e *PubSubEngine) SubscribePartitions(clientID, topicName string, lastN int, partitions []int) ([]models.Message, error) {
 	topic, err := e.GetTopic(topicName)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, p := range partitions {
+		if !topic.ValidPartition(p) {
+			return nil, ErrInvalidPartition
+		}
+	}
+
 	e.mu.RLock()
 	subscriber, exists := e.Clients[clientID]
 	e.mu.RUnlock()
@@ -150,16 +269,16 @@ func (e *PubSubEngine) Subscribe(clientID, topicName string, lastN int) ([]model
 		return nil, ErrClientNotFound
 	}
 
-	topic.AddSubscriber(subscriber)
+	topic.AddSubscriberToPartitions(subscriber, partitions)
 	subscriber.AddTopic(topicName)
 
-	log.Printf("[INFO] Client %s subscribed to topic %s", clientID, topicName)
+	subscriber.log.Info("Client subscribed", "topic", topicName)
 
 	// Get historical messages if requested
 	var history []models.Message
 	if lastN > 0 {
-		history = topic.GetLastN(lastN)
-		log.Printf("[INFO] Sending %d historical messages to client %s", len(history), clientID)
+		history = topic.GetLastNFromPartitions(lastN, partitions)
+		subscriber.log.Info("Sending historical messages", "topic", topicName, "count", len(history))
 	}
 
 	return history, nil
@@ -182,32 +301,172 @@ func (e *PubSubEngine) Unsubscribe(clientID, topicName string) error {
 		subscriber.RemoveTopic(topicName)
 	}
 
-	log.Printf("[INFO] Client %s unsubscribed from topic %s", clientID, topicName)
+	slog.Info("Client unsubscribed", "client_id", clientID, "topic", topicName)
 	return nil
 }
 
 // Publish publishes a message to a topic
+// The publish span continues the trace in the message's traceparent header, if any
 func (e *PubSubEngine) Publish(topicName string, msg models.Message) error {
+	span := startMessageSpan(e.tracer, "publish "+topicName, trace.SpanKindProducer, &msg,
+		semconv.MessagingOperationName("publish"), semconv.MessagingDestinationName(topicName))
+	defer span.End()
+
+	if e.IsShuttingDown() {
+		recordError(span, ErrShuttingDown)
+		return ErrShuttingDown
+	}
+
 	topic, err := e.GetTopic(topicName)
 	if err != nil {
+		recordError(span, err)
 		return err
 	}
 
+	if topic.IsDuplicate(msg.ID) {
+		slog.Info("Duplicate message suppressed", "topic", topicName, "message_id", msg.ID)
+		span.SetAttributes(attrDuplicate.Bool(true))
+		return ErrDuplicateMessage
+	}
+
 	msg.Timestamp = time.Now()
 	topic.PublishMessage(msg)
 
-	log.Printf("[INFO] Message published to topic %s: id=%s", topicName, msg.ID)
+	slog.Info("Message published", "topic", topicName, "message_id", msg.ID)
 	return nil
 }
 
+// Scheduled Delivery
+
+// Schedule parks a message for delivery to a topic at a future time
+func (e *PubSubEngine) Schedule(topicName string, msg models.Message, deliverAt time.Time) (ScheduledMessage, error) {
+	if e.IsShuttingDown() {
+		return ScheduledMessage{}, ErrShuttingDown
+	}
+
+	topic, err := e.GetTopic(topicName)
+	if err != nil {
+		return ScheduledMessage{}, err
+	}
+
+	now := time.Now()
+	if e.maxDelay > 0 && deliverAt.Sub(now) > e.maxDelay {
+		return ScheduledMessage{}, ErrScheduleTooFar
+	}
+
+	// Deduplicate at schedule time so retried scheduling requests are not delivered twice
+	if topic.IsDuplicate(msg.ID) {
+		slog.Info("Duplicate scheduled message suppressed", "topic", topicName, "message_id", msg.ID)
+		return ScheduledMessage{}, ErrDuplicateMessage
+	}
+
+	sm := &ScheduledMessage{
+		Topic:     topicName,
+		Message:   msg,
+		DeliverAt: deliverAt,
+		CreatedAt: now,
+	}
+	// Copy before Add: once queued, the scheduler goroutine owns sm
+	scheduled := *sm
+	if err := e.scheduler.Add(sm); err != nil {
+		// Not accepted, so a retry must not be suppressed as a duplicate
+		topic.ForgetMessageID(msg.ID)
+		return ScheduledMessage{}, err
+	}
+
+	slog.Info("Message scheduled", "topic", topicName, "message_id", msg.ID, "deliver_at", deliverAt.UTC())
+	return scheduled, nil
+}
+
+// ListScheduled returns a tenant's pending scheduled messages, optionally filtered by topic
+func (e *PubSubEngine) ListScheduled(tenant, topicName string) []ScheduledMessage {
+	if topicName != "" {
+		return e.scheduler.List(TopicKey(tenant, topicName))
+	}
+
+	pending := e.scheduler.List("")
+	result := make([]ScheduledMessage, 0, len(pending))
+	for _, sm := range pending {
+		if smTenant, _ := SplitTopicKey(sm.Topic); smTenant == tenant {
+			result = append(result, sm)
+		}
+	}
+	return result
+}
+
+// GetScheduled returns a tenant's pending scheduled message by message ID
+func (e *PubSubEngine) GetScheduled(tenant, id string) (ScheduledMessage, bool) {
+	pending, exists := e.scheduler.Get(id)
+	if !exists {
+		return ScheduledMessage{}, false
+	}
+	if smTenant, _ := SplitTopicKey(pending.Topic); smTenant != tenant {
+		return ScheduledMessage{}, false
+	}
+	return pending, true
+}
+
+// CancelScheduled cancels a tenant's pending scheduled message by message ID
+func (e *PubSubEngine) CancelScheduled(tenant, id string) (ScheduledMessage, error) {
+	if _, exists := e.GetScheduled(tenant, id); !exists {
+		return ScheduledMessage{}, ErrScheduledNotFound
+	}
+
+	sm, err := e.scheduler.Cancel(id)
+	if err != nil {
+		return ScheduledMessage{}, err
+	}
+
+	// The message was never delivered, so it may be scheduled or published again
+	if topic, err := e.GetTopic(sm.Topic); err == nil {
+		topic.ForgetMessageID(id)
+	}
+
+	slog.Info("Scheduled message cancelled", "topic", sm.Topic, "message_id", id)
+	return *sm, nil
+}
+
+// deliverScheduled fans out a due scheduled message to its topic
+func (e *PubSubEngine) deliverScheduled(sm *ScheduledMessage) {
+	topic, err := e.GetTopic(sm.Topic)
+	if err != nil {
+		slog.Warn("Dropping scheduled message: topic no longer exists", "topic", sm.Topic, "message_id", sm.Message.ID)
+		return
+	}
+
+	msg := sm.Message
+	span := startMessageSpan(e.tracer, "publish "+sm.Topic, trace.SpanKindProducer, &msg,
+		semconv.MessagingOperationName("publish"), semconv.MessagingDestinationName(sm.Topic), attrScheduled.Bool(true))
+	defer span.End()
+
+	msg.Timestamp = time.Now()
+	topic.PublishMessage(msg)
+
+	slog.Info("Scheduled message delivered", "topic", sm.Topic, "message_id", msg.ID)
+}
+
 // Client Management
 
 // RegisterClient registers a new client
-func (e *PubSubEngine) RegisterClient(subscriber *Subscriber) {
+// Client IDs are shared across tenants, so an ID that is already connected is
+// rejected with ErrClientIDInUse rather than taking over the other client's replies.
+// Returns ErrShuttingDown once shutdown has begun, since the drain would not reach the client
+func (e *PubSubEngine) RegisterClient(subscriber *Subscriber) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	// The drain lists clients after marking shutdown, so any client registered before this check is drained
+	if e.IsShuttingDown() {
+		return ErrShuttingDown
+	}
+	if _, exists := e.Clients[subscriber.ClientID]; exists {
+		return ErrClientIDInUse
+	}
+	subscriber.metrics = e.metrics
+	subscriber.tracer = e.tracer
+	subscriber.stampDelivery = e.stampDelivery
 	e.Clients[subscriber.ClientID] = subscriber
-	log.Printf("[INFO] Client registered: %s", subscriber.ClientID)
+	subscriber.log.Info("Client registered")
+	return nil
 }
 
 // UnregisterClient unregisters a client and unsubscribes from all topics
@@ -222,7 +481,10 @@ func (e *PubSubEngine) UnregisterClient(clientID string) {
 	delete(e.Clients, clientID)
 	e.mu.Unlock()
 
-	log.Printf("[INFO] Client unregistered: %s", clientID)
+	subscriber.log.Info("Client unregistered")
+
+	// Nobody is left to receive replies to this client's requests
+	e.cancelClientRequests(clientID)
 
 	// Get topics before unsubscribing
 	topics := subscriber.GetTopics()
@@ -249,23 +511,61 @@ func (e *PubSubEngine) GetClient(clientID string) (*Subscriber, error) {
 	return client, nil
 }
 
+// FindClients returns the connected clients matching a predicate
+func (e *PubSubEngine) FindClients(match func(*Subscriber) bool) []*Subscriber {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	clients := make([]*Subscriber, 0)
+	for _, client := range e.Clients {
+		if match(client) {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
 // Stats and Health
 
-// GetStats returns statistics for all topics
-func (e *PubSubEngine) GetStats() models.StatsResponse {
+// Metrics returns the registry holding the engine's metrics
+// Handlers register their own metrics here so /metrics serves a single registry
+func (e *PubSubEngine) Metrics() *metrics.Registry {
+	return e.registry
+}
+
+// SetTracerProvider traces publishes, fan-outs, enqueues and writes with tp
+// Call before topics are created and clients connect; they keep the tracer they started with
+func (e *PubSubEngine) SetTracerProvider(tp trace.TracerProvider) {
+	e.tracer = tp.Tracer(tracerName)
+}
+
+// GetStats returns statistics for a tenant's topics
+func (e *PubSubEngine) GetStats(tenant string) models.StatsResponse {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	topics := make(map[string]models.TopicStats)
-	for name, topic := range e.Topics {
-		topics[name] = models.TopicStats{
-			Messages:    topic.GetMessageCount(),
-			Subscribers: topic.GetSubscriberCount(),
+	var total models.ThroughputStats
+	for _, topic := range e.Topics {
+		if topic.Tenant != tenant {
+			continue
+		}
+		throughput := topic.GetThroughput()
+		total = addThroughput(total, throughput)
+		topics[topic.Name] = models.TopicStats{
+			Messages:      topic.GetMessageCount(),
+			Subscribers:   topic.GetSubscriberCount(),
+			DedupHits:     topic.GetDedupHits(),
+			Partitions:    topic.GetPartitionStats(),
+			Subscriptions: topic.GetSubscriptionStats(),
+			Throughput:    throughput,
+			Latency:       topic.GetLatency(),
 		}
 	}
 
 	return models.StatsResponse{
-		Topics: topics,
+		Topics:     topics,
+		Throughput: total,
 	}
 }
 
@@ -289,26 +589,116 @@ func (e *PubSubEngine) GetHealth() models.HealthResponse {
 		UptimeSec:   int(time.Since(e.startTime).Seconds()),
 		Topics:      len(e.Topics),
 		Subscribers: uniqueSubscribers,
+		Throughput:  throughputStats(e.publishes, e.deliveries),
+	}
+}
+
+// CheckReady returns ErrShuttingDown once the engine has begun shutting down
+func (e *PubSubEngine) CheckReady() error {
+	if e.IsShuttingDown() {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// CheckLive returns an error when the engine lock cannot be taken within timeout
+// A lock held that long means the engine is wedged and will not recover on its own
+func (e *PubSubEngine) CheckLive(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for !e.mu.TryRLock() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("engine lock not acquired within %s", timeout)
+		}
+		time.Sleep(time.Millisecond)
 	}
+	e.mu.RUnlock()
+	return nil
 }
 
 // Graceful Shutdown
 
-// Shutdown gracefully shuts down the engine
+// Reconnect delays suggested to clients in shutdown notices
+// Each client gets a random delay in this range so they do not all reconnect at once
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 5 * time.Second
+)
+
+// Shutdown drains the engine and closes every client connection
+// New connections and publishes are refused at once. Each client is sent a shutdown
+// notice, then the events already queued for it, then a going-away close frame.
+// Connections still open when the shutdown timeout expires are closed.
+// Calls after the first do nothing
 func (e *PubSubEngine) Shutdown() {
-	log.Println("[INFO] Shutting down PubSub engine...")
+	ctx, cancel := context.WithTimeout(context.Background(), e.drainTimeout)
+	defer cancel()
+
+	e.ShutdownContext(ctx)
+}
+
+// ShutdownContext is like Shutdown, but drains clients until ctx's deadline instead of the shutdown timeout
+// Lets the caller give the engine and the HTTP server one shared deadline
+func (e *PubSubEngine) ShutdownContext(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(e.drainTimeout)
+	}
+	e.shutdownOnce.Do(func() { e.shutdownEngine(deadline) })
+}
+
+func (e *PubSubEngine) shutdownEngine(deadline time.Time) {
+	slog.Info("Shutting down PubSub engine", "drain_timeout", time.Until(deadline).Round(time.Millisecond))
 	close(e.shutdown)
 
+	// Stop delivering scheduled messages
+	if pending := e.scheduler.Len(); pending > 0 {
+		slog.Warn("Discarding undelivered scheduled messages", "count", pending)
+	}
+	e.scheduler.Stop()
+
+	// Flush each client's queue and close it with a going-away code
+	all := func(*Subscriber) bool { return true }
+	for _, client := range e.FindClients(all) {
+		client.log.Info("Draining client connection")
+		client.DisconnectWithNotice(DisconnectShutdown, shutdownNotice(), websocket.CloseGoingAway,
+			"server shutting down", time.Until(deadline))
+	}
+	e.waitForClients(deadline)
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	// Close all client connections
-	for clientID, client := range e.Clients {
-		log.Printf("[INFO] Closing client connection: %s", clientID)
+	// Close connections that did not drain in time
+	for _, client := range e.Clients {
+		client.log.Warn("Closing client connection before it drained")
 		client.Close()
 	}
 
-	log.Println("[INFO] PubSub engine shutdown complete")
+	slog.Info("PubSub engine shutdown complete")
+}
+
+// waitForClients waits until every client has unregistered or deadline passes
+func (e *PubSubEngine) waitForClients(deadline time.Time) {
+	for time.Now().Before(deadline) {
+		e.mu.RLock()
+		remaining := len(e.Clients)
+		e.mu.RUnlock()
+		if remaining == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// shutdownNotice tells a client the server is going away and when to reconnect
+func shutdownNotice() models.ServerMessage {
+	delay := minReconnectDelay + rand.N(maxReconnectDelay-minReconnectDelay)
+	return models.ServerMessage{
+		Type:             "info",
+		Msg:              "shutdown",
+		ReconnectAfterMs: delay.Milliseconds(),
+		Timestamp:        time.Now().UTC().Format(time.RFC3339),
+	}
 }
 
 // IsShuttingDown returns whether the engine is shutting down
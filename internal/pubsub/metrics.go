@@ -0,0 +1,100 @@
+package pubsub
+
+import (
+	"github.com/tarunm/pubsub-system/internal/metrics"
+)
+
+// engineMetrics are the instruments updated by the engine, its topics and subscribers
+// A nil *engineMetrics (topics and subscribers created outside an engine) records nothing
+type engineMetrics struct {
+	published      *metrics.CounterVec // topic
+	delivered      *metrics.CounterVec // topic
+	dropped        *metrics.Counter
+	disconnects    *metrics.CounterVec // reason
+	queueDepth     *metrics.Histogram
+	fanoutDuration *metrics.Histogram
+	latency        *metrics.HistogramVec // topic
+}
+
+// newEngineMetrics registers the engine's metrics with registry
+func newEngineMetrics(registry *metrics.Registry, e *PubSubEngine) *engineMetrics {
+	m := &engineMetrics{
+		published: registry.NewCounterVec("pubsub_messages_published_total",
+			"Messages published to a topic, including scheduled deliveries.", "topic"),
+		delivered: registry.NewCounterVec("pubsub_messages_delivered_total",
+			"Messages queued for delivery to a subscriber.", "topic"),
+		dropped: registry.NewCounter("pubsub_messages_dropped_total",
+			"Messages dropped because a subscriber queue was full."),
+		disconnects: registry.NewCounterVec("pubsub_client_disconnects_total",
+			"Clients disconnected by the server, by reason (e.g. slow_consumer).", "reason"),
+		queueDepth: registry.NewHistogram("pubsub_subscriber_queue_depth",
+			"Messages waiting in a subscriber queue, observed on each enqueue.",
+			[]float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000}),
+		fanoutDuration: registry.NewHistogram("pubsub_fanout_duration_seconds",
+			"Time to store a published message and queue it for every subscriber.",
+			metrics.ExponentialBuckets(0.00001, 4, 10)),
+		latency: registry.NewHistogramVec("pubsub_delivery_latency_seconds",
+			"Time from publish until the event is written to a subscriber's connection.",
+			metrics.ExponentialBuckets(0.0001, 2, 18), "topic"),
+	}
+
+	registry.NewGaugeFunc("pubsub_topics", "Topics across all tenants.", func() float64 {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+		return float64(len(e.Topics))
+	})
+	registry.NewGaugeFunc("pubsub_clients", "Registered WebSocket clients.", func() float64 {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+		return float64(len(e.Clients))
+	})
+	registry.NewGaugeFunc("pubsub_scheduled_messages", "Scheduled messages waiting for delivery.", func() float64 {
+		return float64(e.scheduler.Len())
+	})
+	return m
+}
+
+// instrumentTopic attaches a topic's published and delivered counters and the fan-out histogram
+func (m *engineMetrics) instrumentTopic(topic *Topic, key string) {
+	if m == nil {
+		return
+	}
+	topic.published = m.published.With(key)
+	topic.delivered = m.delivered.With(key)
+	topic.fanoutDuration = m.fanoutDuration
+	topic.latency = m.latency.With(key)
+}
+
+// removeTopic drops a deleted topic's series
+func (m *engineMetrics) removeTopic(key string) {
+	if m == nil {
+		return
+	}
+	m.published.Delete(key)
+	m.delivered.Delete(key)
+	m.latency.Delete(key)
+}
+
+// observeQueue records a subscriber's queue depth after an enqueue
+func (m *engineMetrics) observeQueue(depth int) {
+	if m == nil {
+		return
+	}
+	m.queueDepth.Observe(float64(depth))
+}
+
+// messageDropped records a message dropped from a full subscriber queue
+func (m *engineMetrics) messageDropped() {
+	if m == nil {
+		return
+	}
+	m.dropped.Inc()
+}
+
+// clientDisconnected records a client the server disconnected
+func (m *engineMetrics) clientDisconnected(reason string) {
+	if m == nil {
+		return
+	}
+	m.disconnects.With(reason).Inc()
+}
@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+const (
+	// MaxPartitions is the maximum number of partitions a topic can have
+	MaxPartitions = 256
+)
+
+// Partition is an independently ordered slice of a topic's message stream
+// Each partition has its own history and offset space
+type Partition struct {
+	ID         int
+	Buffer     *RingBuffer
+	NextOffset int64
+	mu         sync.Mutex
+}
+
+// NewPartition creates a partition with a history buffer of the given size
+func NewPartition(id int, bufferSize int) *Partition {
+	return &Partition{
+		ID:     id,
+		Buffer: NewRingBuffer(bufferSize),
+	}
+}
+
+// GetNextOffset returns the offset the next message will be assigned
+func (p *Partition) GetNextOffset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.NextOffset
+}
+
+// PartitionForKey returns the partition a key is routed to out of n partitions
+// The mapping is stable, so all messages for a key land on one partition
+func PartitionForKey(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// mergeByTimestamp merges per-partition histories (each in offset order) into a
+// single chronological slice while preserving the order within each partition
+func mergeByTimestamp(histories [][]models.Message) []models.Message {
+	total := 0
+	for _, h := range histories {
+		total += len(h)
+	}
+
+	merged := make([]models.Message, 0, total)
+	heads := make([]int, len(histories))
+	for len(merged) < total {
+		next := -1
+		for i, h := range histories {
+			if heads[i] >= len(h) {
+				continue
+			}
+			if next == -1 || h[heads[i]].Timestamp.Before(histories[next][heads[next]].Timestamp) {
+				next = i
+			}
+		}
+		merged = append(merged, histories[next][heads[next]])
+		heads[next]++
+	}
+	return merged
+}
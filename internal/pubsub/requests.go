@@ -0,0 +1,153 @@
+package pubsub
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+const (
+	// InboxPrefix prefixes auto-generated reply inboxes
+	InboxPrefix = "_inbox."
+)
+
+var (
+	// ErrNoResponders is returned when a request is sent to a topic without subscribers
+	ErrNoResponders = errors.New("no responders")
+
+	// ErrRequestTimeout is reported to the requester when no reply arrives in time
+	ErrRequestTimeout = errors.New("request timed out")
+
+	// ErrRequestNotFound is returned when replying to an inbox that expired or was already answered
+	ErrRequestNotFound = errors.New("request not found")
+)
+
+// pendingRequest tracks a request awaiting its first reply
+type pendingRequest struct {
+	inbox     string
+	clientID  string
+	requestID string
+	topic     string
+	timer     *time.Timer
+}
+
+// Request publishes a message with a private reply inbox and routes the first
+// reply back to the requesting client. If no reply arrives within timeout the
+// requester receives a REQUEST_TIMEOUT error. Returns the generated inbox.
+func (e *PubSubEngine) Request(clientID, requestID, topicName string, msg models.Message, timeout time.Duration) (string, error) {
+	topic, err := e.GetTopic(topicName)
+	if err != nil {
+		return "", err
+	}
+	if topic.GetSubscriberCount() == 0 {
+		return "", ErrNoResponders
+	}
+
+	// Register before publishing so an immediate reply finds the inbox
+	inbox := InboxPrefix + uuid.New().String()
+	pending := &pendingRequest{
+		inbox:     inbox,
+		clientID:  clientID,
+		requestID: requestID,
+		topic:     topic.Name,
+	}
+
+	e.requestsMu.Lock()
+	e.requests[inbox] = pending
+	pending.timer = time.AfterFunc(timeout, func() {
+		e.expireRequest(inbox)
+	})
+	e.requestsMu.Unlock()
+
+	msg.ReplyTo = inbox
+	if err := e.Publish(topicName, msg); err != nil {
+		e.takeRequest(inbox)
+		return "", err
+	}
+
+	slog.Info("Request sent", "client_id", clientID, "topic", topicName, "inbox", inbox)
+	return inbox, nil
+}
+
+// Reply delivers a reply to the requester waiting on inbox
+// Only the first reply is delivered; later replies return ErrRequestNotFound
+func (e *PubSubEngine) Reply(inbox string, msg models.Message) error {
+	pending := e.takeRequest(inbox)
+	if pending == nil {
+		return ErrRequestNotFound
+	}
+
+	requester, err := e.GetClient(pending.clientID)
+	if err != nil {
+		slog.Warn("Dropping reply: requester disconnected", "client_id", pending.clientID, "inbox", inbox)
+		return err
+	}
+
+	msg.Timestamp = time.Now()
+	requester.SendMessage(models.ServerMessage{
+		Type:      "reply",
+		RequestID: pending.requestID,
+		Topic:     pending.topic,
+		Message:   &msg,
+		Timestamp: msg.Timestamp.UTC().Format(time.RFC3339),
+	})
+
+	requester.log.Info("Reply routed", "inbox", inbox)
+	return nil
+}
+
+// takeRequest removes and returns a pending request, stopping its timeout
+func (e *PubSubEngine) takeRequest(inbox string) *pendingRequest {
+	e.requestsMu.Lock()
+	defer e.requestsMu.Unlock()
+
+	pending, exists := e.requests[inbox]
+	if !exists {
+		return nil
+	}
+	delete(e.requests, inbox)
+	pending.timer.Stop()
+	return pending
+}
+
+// expireRequest notifies the requester that no reply arrived in time
+func (e *PubSubEngine) expireRequest(inbox string) {
+	pending := e.takeRequest(inbox)
+	if pending == nil {
+		return
+	}
+
+	slog.Warn("Request timed out", "client_id", pending.clientID, "topic", pending.topic, "inbox", inbox)
+
+	requester, err := e.GetClient(pending.clientID)
+	if err != nil {
+		return
+	}
+
+	requester.SendMessage(models.ServerMessage{
+		Type:      "error",
+		RequestID: pending.requestID,
+		Topic:     pending.topic,
+		Error: &models.ErrorInfo{
+			Code:    "REQUEST_TIMEOUT",
+			Message: ErrRequestTimeout.Error(),
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// cancelClientRequests drops all pending requests made by a client
+func (e *PubSubEngine) cancelClientRequests(clientID string) {
+	e.requestsMu.Lock()
+	defer e.requestsMu.Unlock()
+
+	for inbox, pending := range e.requests {
+		if pending.clientID == clientID {
+			pending.timer.Stop()
+			delete(e.requests, inbox)
+		}
+	}
+}
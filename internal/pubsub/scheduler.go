@@ -0,0 +1,252 @@
+package pubsub
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+var (
+	// ErrAlreadyScheduled is returned when a message with the same ID is already parked
+	ErrAlreadyScheduled = errors.New("message already scheduled")
+
+	// ErrScheduledNotFound is returned when a scheduled message does not exist
+	ErrScheduledNotFound = errors.New("scheduled message not found")
+
+	// ErrSchedulerFull is returned when the scheduler has reached its capacity
+	ErrSchedulerFull = errors.New("scheduler is full")
+)
+
+// ScheduledMessage is a message parked until its delivery time
+type ScheduledMessage struct {
+	Topic     string
+	Message   models.Message
+	DeliverAt time.Time
+	CreatedAt time.Time
+	index     int // position in the heap, maintained by scheduleQueue
+}
+
+// scheduleQueue is a min-heap of scheduled messages ordered by delivery time
+type scheduleQueue []*ScheduledMessage
+
+func (q scheduleQueue) Len() int { return len(q) }
+
+func (q scheduleQueue) Less(i, j int) bool {
+	return q[i].DeliverAt.Before(q[j].DeliverAt)
+}
+
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduleQueue) Push(x interface{}) {
+	sm := x.(*ScheduledMessage)
+	sm.index = len(*q)
+	*q = append(*q, sm)
+}
+
+func (q *scheduleQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	sm := old[n-1]
+	old[n-1] = nil
+	sm.index = -1
+	*q = old[:n-1]
+	return sm
+}
+
+// Scheduler holds messages in a time-ordered queue and hands them to a
+// delivery callback once they are due
+type Scheduler struct {
+	queue   scheduleQueue
+	byID    map[string]*ScheduledMessage
+	maxSize int // 0 = unlimited
+	deliver func(*ScheduledMessage)
+	wake    chan struct{}
+	stop    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a scheduler that calls deliver for each due message
+func NewScheduler(maxSize int, deliver func(*ScheduledMessage)) *Scheduler {
+	return &Scheduler{
+		queue:   make(scheduleQueue, 0),
+		byID:    make(map[string]*ScheduledMessage),
+		maxSize: maxSize,
+		deliver: deliver,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start runs the scheduling loop in a background goroutine
+func (s *Scheduler) Start() {
+	go s.run()
+}
+
+// Stop terminates the scheduling loop; pending messages are discarded
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Add parks a message until its delivery time
+func (s *Scheduler) Add(sm *ScheduledMessage) error {
+	s.mu.Lock()
+	if _, exists := s.byID[sm.Message.ID]; exists {
+		s.mu.Unlock()
+		return ErrAlreadyScheduled
+	}
+	if s.maxSize > 0 && len(s.queue) >= s.maxSize {
+		s.mu.Unlock()
+		return ErrSchedulerFull
+	}
+
+	heap.Push(&s.queue, sm)
+	s.byID[sm.Message.ID] = sm
+	s.mu.Unlock()
+
+	s.notify()
+	return nil
+}
+
+// Cancel removes a scheduled message before it is delivered
+func (s *Scheduler) Cancel(id string) (*ScheduledMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sm, exists := s.byID[id]
+	if !exists {
+		return nil, ErrScheduledNotFound
+	}
+
+	heap.Remove(&s.queue, sm.index)
+	delete(s.byID, id)
+	return sm, nil
+}
+
+// Get returns a pending scheduled message by message ID
+func (s *Scheduler) Get(id string) (ScheduledMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sm, exists := s.byID[id]
+	if !exists {
+		return ScheduledMessage{}, false
+	}
+	return *sm, true
+}
+
+// RemoveTopic drops all scheduled messages for a topic and returns how many were removed
+func (s *Scheduler) RemoveTopic(topic string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, sm := range s.byID {
+		if sm.Topic == topic {
+			heap.Remove(&s.queue, sm.index)
+			delete(s.byID, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// List returns scheduled messages ordered by delivery time
+// An empty topic returns messages for all topics
+func (s *Scheduler) List(topic string) []ScheduledMessage {
+	s.mu.Lock()
+	result := make([]ScheduledMessage, 0, len(s.queue))
+	for _, sm := range s.queue {
+		if topic == "" || sm.Topic == topic {
+			result = append(result, *sm)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DeliverAt.Before(result[j].DeliverAt)
+	})
+	return result
+}
+
+// Len returns the number of pending scheduled messages
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// notify wakes the scheduling loop so it can re-evaluate the next deadline
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run waits for the earliest deadline and delivers due messages
+func (s *Scheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		due := s.popDue(time.Now())
+		for _, sm := range due {
+			s.deliver(sm)
+		}
+
+		timer.Reset(s.nextWait())
+
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// popDue removes and returns all messages whose delivery time has passed
+func (s *Scheduler) popDue(now time.Time) []*ScheduledMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*ScheduledMessage
+	for len(s.queue) > 0 && !s.queue[0].DeliverAt.After(now) {
+		sm := heap.Pop(&s.queue).(*ScheduledMessage)
+		delete(s.byID, sm.Message.ID)
+		due = append(due, sm)
+	}
+	return due
+}
+
+// nextWait returns how long to sleep until the earliest scheduled message
+func (s *Scheduler) nextWait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return time.Hour
+	}
+	wait := time.Until(s.queue[0].DeliverAt)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
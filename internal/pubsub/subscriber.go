@@ -1,12 +1,18 @@
 package pubsub
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/auth"
 	"github.com/tarunm/pubsub-system/internal/models"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -23,14 +29,48 @@ const (
 	PingPeriod = 30 * time.Second
 )
 
+// Reasons the server disconnects a client
+const (
+	DisconnectSlowConsumer      = "slow_consumer"
+	DisconnectCredentialExpired = "credential_expired"
+	DisconnectKeyRevoked        = "key_revoked"
+	DisconnectAdmin             = "admin"
+	DisconnectShutdown          = "shutdown"
+	DisconnectConnectionLimit   = "connection_limit"
+)
+
+// closeRequest asks WritePump to write the queued messages and then a close frame
+type closeRequest struct {
+	notice   *models.ServerMessage // Written ahead of the queued messages (nil = none)
+	code     int                   // WebSocket close code
+	text     string
+	deadline time.Time // Queued messages not written by then are dropped
+}
+
 // Subscriber represents a WebSocket client subscribed to topics
 type Subscriber struct {
 	ClientID    string
+	ClientIP    string
 	Conn        *websocket.Conn
 	Topics      map[string]bool
 	MessageChan chan models.ServerMessage
+	principal   *auth.Principal // Set once the client authenticates
+	principalCh chan struct{}   // Signalled when the principal changes
+	closeCh     chan closeRequest
+	deliveries  map[string]*deliveryStats // Delivery stats of subscribed topics, by topic name
 	mu          sync.Mutex
 	closed      bool
+	closeReason string         // Why the server closed the connection (empty = client disconnected)
+	metrics     *engineMetrics // Set when the client is registered with an engine
+	tracer      trace.Tracer   // The engine's tracer once registered, or a no-op tracer
+	log         *slog.Logger   // Logger with the client ID attached
+	// Set when registered: stamp published_at and delivered_at on events
+	stampDelivery bool
+	// Activity
+	connectedAt  time.Time
+	lastActivity atomic.Int64 // Unix nanoseconds of the last frame read or written
+	sent         atomic.Int64 // Messages written to the connection
+	dropped      atomic.Int64 // Messages discarded because the queue was full
 	// Configuration
 	queueSize  int
 	pingPeriod time.Duration
@@ -45,27 +85,36 @@ func NewSubscriber(clientID string, conn *websocket.Conn) *Subscriber {
 
 // NewSubscriberWithConfig creates a new subscriber with custom configuration
 func NewSubscriberWithConfig(clientID string, conn *websocket.Conn, queueSize int, pingPeriod, pongWait, writeWait time.Duration) *Subscriber {
-	return &Subscriber{
+	s := &Subscriber{
 		ClientID:    clientID,
 		Conn:        conn,
 		Topics:      make(map[string]bool),
 		MessageChan: make(chan models.ServerMessage, queueSize),
+		principalCh: make(chan struct{}, 1),
+		closeCh:     make(chan closeRequest, 1),
+		deliveries:  make(map[string]*deliveryStats),
 		closed:      false,
+		tracer:      noopTracer,
+		log:         slog.With("client_id", clientID),
+		connectedAt: time.Now(),
 		queueSize:   queueSize,
 		pingPeriod:  pingPeriod,
 		pongWait:    pongWait,
 		writeWait:   writeWait,
 	}
+	s.lastActivity.Store(s.connectedAt.UnixNano())
+	return s
 }
 
 // SendMessage sends a message to the subscriber
 // Implements backpressure handling: drops oldest message if queue is full
-func (s *Subscriber) SendMessage(msg models.ServerMessage) {
+// Returns whether msg was queued
+func (s *Subscriber) SendMessage(msg models.ServerMessage) (queued bool) {
 	// Use defer to ensure we handle closed state properly
 	defer func() {
 		// Recover from any panic caused by sending on closed channel
 		if r := recover(); r != nil {
-			log.Printf("[DEBUG] SendMessage recovered from panic for client %s (likely closed)", s.ClientID)
+			s.log.Debug("SendMessage recovered from panic (likely closed)")
 		}
 	}()
 
@@ -73,28 +122,38 @@ func (s *Subscriber) SendMessage(msg models.ServerMessage) {
 	s.mu.Lock()
 	if s.closed {
 		s.mu.Unlock()
-		return
+		return false
 	}
 	s.mu.Unlock()
 
+	// Count an event as queued before WritePump can take it
+	delivery := s.deliveryFor(msg)
+	delivery.enqueued()
+
 	select {
 	case s.MessageChan <- msg:
 		// Message queued successfully
+		s.metrics.observeQueue(len(s.MessageChan))
+		return true
 	default:
 		// Check again if closed before backpressure handling
 		s.mu.Lock()
 		if s.closed {
 			s.mu.Unlock()
-			return
+			delivery.discarded()
+			return false
 		}
 		s.mu.Unlock()
 
 		// Queue full - implement backpressure policy
-		log.Printf("[WARN] Slow consumer detected: client_id=%s, dropping oldest message", s.ClientID)
+		s.log.Warn("Slow consumer detected, dropping oldest message")
 
 		// Drop oldest message and try again
 		select {
-		case <-s.MessageChan: // Remove oldest
+		case oldest := <-s.MessageChan: // Remove oldest
+			s.dropped.Add(1)
+			s.deliveryFor(oldest).discarded()
+			s.metrics.messageDropped()
 		default:
 		}
 
@@ -102,6 +161,8 @@ func (s *Subscriber) SendMessage(msg models.ServerMessage) {
 		select {
 		case s.MessageChan <- msg:
 			// Success after dropping oldest
+			s.metrics.observeQueue(len(s.MessageChan))
+			return true
 		default:
 			// Still full - send error and mark for disconnect
 			errMsg := models.ServerMessage{
@@ -118,11 +179,46 @@ func (s *Subscriber) SendMessage(msg models.ServerMessage) {
 			default:
 			}
 			// Close the subscriber
-			go s.Close()
+			s.dropped.Add(1)
+			delivery.discarded()
+			s.metrics.messageDropped()
+			go s.Disconnect(DisconnectSlowConsumer)
+			return false
 		}
 	}
 }
 
+// enqueue queues a published event inside an enqueue span
+// The subscriber gets its own copy of the message so its trace continues from this span
+func (s *Subscriber) enqueue(event models.ServerMessage) bool {
+	msg := *event.Message
+	span := startMessageSpan(s.tracer, "enqueue "+event.Topic, trace.SpanKindInternal, &msg,
+		semconv.MessagingDestinationName(event.Topic), semconv.MessagingClientID(s.ClientID))
+	defer span.End()
+
+	event.Message = &msg
+	queued := s.SendMessage(event)
+	if !queued {
+		span.SetAttributes(attrDropped.Bool(true))
+	}
+	return queued
+}
+
+// startWrite starts the span for writing an event to the client
+// The event's traceparent is pointed at the span so the consumer continues the trace from it.
+// Other messages are not traced and get a no-op span
+func (s *Subscriber) startWrite(message *models.ServerMessage) trace.Span {
+	if message.Type != "event" || message.Message == nil {
+		return trace.SpanFromContext(context.Background())
+	}
+
+	msg := *message.Message
+	span := startMessageSpan(s.tracer, "send "+message.Topic, trace.SpanKindInternal, &msg,
+		semconv.MessagingDestinationName(message.Topic), semconv.MessagingClientID(s.ClientID))
+	message.Message = &msg
+	return span
+}
+
 // WritePump sends messages from MessageChan to WebSocket
 // Also handles heartbeat/ping messages
 func (s *Subscriber) WritePump() {
@@ -140,23 +236,96 @@ func (s *Subscriber) WritePump() {
 				return
 			}
 
-			s.Conn.SetWriteDeadline(time.Now().Add(s.writeWait))
-			if err := s.Conn.WriteJSON(message); err != nil {
-				log.Printf("[ERROR] Write error for client %s: %v", s.ClientID, err)
+			if err := s.write(message, time.Now().Add(s.writeWait)); err != nil {
 				return
 			}
 
+		case req := <-s.closeCh:
+			s.writeClose(req)
+			return
+
 		case <-ticker.C:
 			// Send WebSocket ping frame (not a JSON message)
 			s.Conn.SetWriteDeadline(time.Now().Add(s.writeWait))
 			if err := s.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Printf("[ERROR] Heartbeat error for client %s: %v", s.ClientID, err)
+				s.log.Error("Heartbeat error", "error", err)
 				return
 			}
 		}
 	}
 }
 
+// write writes a queued message to the connection
+func (s *Subscriber) write(message models.ServerMessage, deadline time.Time) error {
+	delivery := s.deliveryFor(message)
+	span := s.startWrite(&message)
+	defer span.End()
+
+	now := time.Now()
+	if s.stampDelivery && message.Type == "event" && message.Message != nil {
+		message.PublishedAt = message.Message.Timestamp.UTC().Format(time.RFC3339Nano)
+		message.DeliveredAt = now.UTC().Format(time.RFC3339Nano)
+	}
+
+	s.Conn.SetWriteDeadline(deadline)
+	if err := s.Conn.WriteJSON(message); err != nil {
+		s.log.Error("Write error", "error", err)
+		recordError(span, err)
+		return err
+	}
+	s.sent.Add(1)
+	delivery.written(message.Message, now)
+	s.RecordActivity()
+	return nil
+}
+
+// writeClose writes the notice and the messages already queued, then a close frame
+func (s *Subscriber) writeClose(req closeRequest) {
+	if req.notice != nil {
+		if err := s.write(*req.notice, req.deadline); err != nil {
+			return
+		}
+	}
+	for queued := len(s.MessageChan); queued > 0 && time.Now().Before(req.deadline); queued-- {
+		if err := s.write(<-s.MessageChan, req.deadline); err != nil {
+			return
+		}
+	}
+
+	message := websocket.FormatCloseMessage(req.code, req.text)
+	if err := s.Conn.WriteControl(websocket.CloseMessage, message, req.deadline); err != nil {
+		s.log.Debug("Failed to write close frame", "error", err)
+	}
+}
+
+// trackDelivery attributes queued and written events of a topic to a subscription's stats
+func (s *Subscriber) trackDelivery(topicName string, delivery *deliveryStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[topicName] = delivery
+}
+
+// untrackDelivery stops attributing a topic's events to a subscription that was removed
+// A newer subscription to the topic keeps its stats
+func (s *Subscriber) untrackDelivery(topicName string, delivery *deliveryStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.deliveries[topicName] == delivery {
+		delete(s.deliveries, topicName)
+	}
+}
+
+// deliveryFor returns the stats of the subscription an event belongs to
+// Returns nil for other messages and for events of topics no longer subscribed
+func (s *Subscriber) deliveryFor(msg models.ServerMessage) *deliveryStats {
+	if msg.Type != "event" || msg.Message == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deliveries[msg.Topic]
+}
+
 // AddTopic adds a topic to the subscriber's topic list
 func (s *Subscriber) AddTopic(topicName string) {
 	s.mu.Lock()
@@ -171,6 +340,13 @@ func (s *Subscriber) RemoveTopic(topicName string) {
 	delete(s.Topics, topicName)
 }
 
+// HasTopic reports whether the subscriber is subscribed to a topic
+func (s *Subscriber) HasTopic(topicName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Topics[topicName]
+}
+
 // GetTopics returns a copy of the subscriber's topics
 func (s *Subscriber) GetTopics() []string {
 	s.mu.Lock()
@@ -200,6 +376,129 @@ func (s *Subscriber) Close() {
 	s.Conn.Close()
 }
 
+// Disconnect closes the connection on the server's initiative, recording why
+// Only the first reason is kept
+func (s *Subscriber) Disconnect(reason string) {
+	s.recordDisconnect(reason)
+	s.Close()
+}
+
+// DisconnectWithCode is like Disconnect, but first writes the messages already queued
+// and then a WebSocket close frame with code and text (at most 123 bytes)
+// Messages not written within timeout are dropped
+func (s *Subscriber) DisconnectWithCode(reason string, code int, text string, timeout time.Duration) {
+	s.requestClose(reason, closeRequest{code: code, text: text, deadline: time.Now().Add(timeout)})
+}
+
+// DisconnectWithNotice is like DisconnectWithCode, but first writes notice ahead of the queued messages
+func (s *Subscriber) DisconnectWithNotice(reason string, notice models.ServerMessage, code int, text string, timeout time.Duration) {
+	s.requestClose(reason, closeRequest{notice: &notice, code: code, text: text, deadline: time.Now().Add(timeout)})
+}
+
+// requestClose hands req to WritePump unless the connection is already closed or closing
+func (s *Subscriber) requestClose(reason string, req closeRequest) {
+	if !s.recordDisconnect(reason) {
+		return
+	}
+
+	select {
+	case s.closeCh <- req:
+	default:
+	}
+}
+
+// recordDisconnect records why the server is closing the connection
+// Returns false if the connection is already closed or closing
+func (s *Subscriber) recordDisconnect(reason string) bool {
+	s.mu.Lock()
+	first := s.closeReason == "" && !s.closed
+	if first {
+		s.closeReason = reason
+	}
+	s.mu.Unlock()
+
+	if first {
+		s.metrics.clientDisconnected(reason)
+	}
+	return first
+}
+
+// CloseReason returns the reason passed to Disconnect, or empty if the server did not force the close
+func (s *Subscriber) CloseReason() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeReason
+}
+
+// SetPrincipal records the identity the client authenticated as
+// Replacing the principal (e.g. when a key's expiry changes) notifies PrincipalChanged
+func (s *Subscriber) SetPrincipal(principal *auth.Principal) {
+	s.mu.Lock()
+	s.principal = principal
+	s.mu.Unlock()
+
+	select {
+	case s.principalCh <- struct{}{}:
+	default:
+	}
+}
+
+// PrincipalChanged returns a channel that receives after each SetPrincipal call
+func (s *Subscriber) PrincipalChanged() <-chan struct{} {
+	return s.principalCh
+}
+
+// GetPrincipal returns the identity the client authenticated as
+// Returns the anonymous principal if the client has not authenticated
+func (s *Subscriber) GetPrincipal() *auth.Principal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.principal == nil {
+		return auth.AnonymousPrincipal()
+	}
+	return s.principal
+}
+
+// RecordActivity marks the client as active now
+// Called for every frame read from or written to the connection
+func (s *Subscriber) RecordActivity() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// Info returns a snapshot of the client's connection, subscriptions and delivery counters
+func (s *Subscriber) Info() models.ClientInfo {
+	principal := s.GetPrincipal()
+
+	topics := s.GetTopics()
+	for i, key := range topics {
+		_, topics[i] = SplitTopicKey(key)
+	}
+	sort.Strings(topics)
+
+	info := models.ClientInfo{
+		ID:              s.ClientID,
+		ClientIP:        s.ClientIP,
+		Principal:       principal.String(),
+		Tenant:          principal.Tenant,
+		ConnectedAt:     s.connectedAt.UTC().Format(time.RFC3339Nano),
+		LastActivity:    time.Unix(0, s.lastActivity.Load()).UTC().Format(time.RFC3339Nano),
+		Topics:          topics,
+		QueueDepth:      len(s.MessageChan),
+		QueueSize:       cap(s.MessageChan),
+		MessagesSent:    s.sent.Load(),
+		MessagesDropped: s.dropped.Load(),
+	}
+	if s.Conn != nil {
+		info.RemoteAddr = s.Conn.RemoteAddr().String()
+	}
+	return info
+}
+
+// Logger returns a logger that tags records with the client ID
+func (s *Subscriber) Logger() *slog.Logger {
+	return s.log
+}
+
 // IsClosed returns whether the subscriber is closed
 func (s *Subscriber) IsClosed() bool {
 	s.mu.Lock()
@@ -0,0 +1,174 @@
+package pubsub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tarunm/pubsub-system/internal/metrics"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// Subscription binds a subscriber to a topic, optionally limited to a subset of partitions
+type Subscription struct {
+	Subscriber *Subscriber
+	Partitions map[int]bool   // nil = all partitions
+	delivery   *deliveryStats // Events of the topic from fan-out to the client
+
+	// Publishers fan out concurrently, so events are put in offset order here
+	next    map[int]int64                 // Per partition, the offset to enqueue next
+	pending map[eventPosition]fanoutEvent // Events that arrived ahead of an earlier offset
+	mu      sync.Mutex
+}
+
+// fanoutEvent is an event being fanned out with its payload size, measured once per publish
+type fanoutEvent struct {
+	message models.ServerMessage
+	size    uint64
+}
+
+// eventPosition identifies an event within a topic
+type eventPosition struct {
+	partition int
+	offset    int64
+}
+
+// NewSubscription creates a subscription for the given partitions
+// An empty partition list subscribes to all partitions
+func NewSubscription(sub *Subscriber, partitions []int) *Subscription {
+	s := &Subscription{
+		Subscriber: sub,
+		delivery:   newDeliveryStats(),
+		next:       make(map[int]int64),
+		pending:    make(map[eventPosition]fanoutEvent),
+	}
+	if len(partitions) > 0 {
+		s.Partitions = make(map[int]bool, len(partitions))
+		for _, p := range partitions {
+			s.Partitions[p] = true
+		}
+	}
+	return s
+}
+
+// Includes reports whether the subscription receives messages from a partition
+func (s *Subscription) Includes(partition int) bool {
+	return s.Partitions == nil || s.Partitions[partition]
+}
+
+// deliver passes a fanned-out event to enqueue in offset order within its partition
+// An event that arrives before an earlier offset is held until that offset is delivered;
+// events published before the subscription began are skipped
+func (s *Subscription) deliver(event fanoutEvent, enqueue func(fanoutEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	partition, offset := event.message.Message.Partition, event.message.Message.Offset
+	switch {
+	case offset < s.next[partition]:
+		return
+	case offset > s.next[partition]:
+		s.pending[eventPosition{partition, offset}] = event
+		return
+	}
+
+	for {
+		enqueue(event)
+		s.next[partition]++
+
+		position := eventPosition{partition, s.next[partition]}
+		held, exists := s.pending[position]
+		if !exists {
+			return
+		}
+		delete(s.pending, position)
+		event = held
+	}
+}
+
+// Stats returns the subscription's delivery counters and how far it trails the topic's partitions
+func (s *Subscription) Stats(partitions []*Partition) models.SubscriptionStats {
+	d := s.delivery
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := models.SubscriptionStats{
+		ClientID:   s.Subscriber.ClientID,
+		Delivered:  d.delivered,
+		Dropped:    d.dropped,
+		QueueDepth: d.queued,
+	}
+	for _, p := range partitions {
+		if s.Includes(p.ID) {
+			stats.Lag += max(p.GetNextOffset()-d.next[p.ID], 0)
+		}
+	}
+	if !d.lastDelivery.IsZero() {
+		stats.LastDelivery = d.lastDelivery.UTC().Format(time.RFC3339Nano)
+	}
+	return stats
+}
+
+// deliveryStats follows a subscription's events through the subscriber's queue
+// The queue methods are safe to call on a nil receiver, which tracks nothing
+type deliveryStats struct {
+	delivered    int64         // Events written to the client
+	dropped      int64         // Events discarded because the queue was full
+	queued       int           // Events waiting in the queue
+	start        map[int]int64 // Per partition, the head when the subscription began
+	next         map[int]int64 // Per partition, the offset after the last one written to the client
+	lastDelivery time.Time
+	latency      *metrics.Histogram // The topic's publish-to-write latency (nil = not recorded)
+	mu           sync.Mutex
+}
+
+func newDeliveryStats() *deliveryStats {
+	return &deliveryStats{start: make(map[int]int64), next: make(map[int]int64)}
+}
+
+// begin records a partition's head when the subscription begins
+// Messages published before it are replayed history: not lag, and not timed
+func (d *deliveryStats) begin(partition int, offset int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.start[partition] = offset
+	d.next[partition] = max(d.next[partition], offset)
+}
+
+// enqueued records an event being added to the queue
+func (d *deliveryStats) enqueued() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queued++
+}
+
+// discarded records a queued event that was dropped instead of written
+func (d *deliveryStats) discarded() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dropped++
+	d.queued = max(d.queued-1, 0)
+}
+
+// written records an event taken from the queue and written to the client at now
+func (d *deliveryStats) written(msg *models.Message, now time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.delivered++
+	d.queued = max(d.queued-1, 0) // Events queued before a re-subscribe were counted by the old subscription
+	d.next[msg.Partition] = max(d.next[msg.Partition], msg.Offset+1)
+	d.lastDelivery = now
+	live := msg.Offset >= d.start[msg.Partition]
+	d.mu.Unlock()
+
+	if live && !msg.Timestamp.IsZero() {
+		d.latency.Observe(now.Sub(msg.Timestamp).Seconds())
+	}
+}
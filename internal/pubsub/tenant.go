@@ -0,0 +1,37 @@
+package pubsub
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// TenantSeparator joins a tenant name and topic name in engine topic keys
+	// Topic names may not contain it, so keys from different tenants never collide
+	TenantSeparator = "/"
+)
+
+// ErrTopicLimit is returned when a tenant already has its maximum number of topics
+var ErrTopicLimit = errors.New("tenant topic limit reached")
+
+// TopicKey returns the engine key for a topic within a tenant
+// The default tenant ("") uses the bare topic name
+func TopicKey(tenant, name string) string {
+	if tenant == "" {
+		return name
+	}
+	return tenant + TenantSeparator + name
+}
+
+// SplitTopicKey returns the tenant and topic name encoded in a topic key
+func SplitTopicKey(key string) (string, string) {
+	if tenant, name, found := strings.Cut(key, TenantSeparator); found {
+		return tenant, name
+	}
+	return "", key
+}
+
+// ValidTopicName reports whether a client-supplied topic name can be used
+func ValidTopicName(name string) bool {
+	return name != "" && !strings.Contains(name, TenantSeparator)
+}
@@ -0,0 +1,78 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tarunm/pubsub-system/internal/metrics"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// tickRates updates the engine's and every topic's moving average rates until shutdown
+func (e *PubSubEngine) tickRates() {
+	ticker := time.NewTicker(metrics.RateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.publishes.Tick()
+			e.deliveries.Tick()
+
+			e.mu.RLock()
+			for _, topic := range e.Topics {
+				topic.publishes.Tick()
+				topic.deliveries.Tick()
+			}
+			e.mu.RUnlock()
+		case <-e.shutdown:
+			return
+		}
+	}
+}
+
+// throughputStats reports a pair of publish and delivery meters
+func throughputStats(publishes, deliveries *metrics.Meter) models.ThroughputStats {
+	publishRate, bytesInRate := publishes.Rates()
+	deliveryRate, bytesOutRate := deliveries.Rates()
+	return models.ThroughputStats{
+		PublishRate:  modelRates(publishRate),
+		DeliveryRate: modelRates(deliveryRate),
+		BytesIn:      publishes.Bytes(),
+		BytesOut:     deliveries.Bytes(),
+		BytesInRate:  modelRates(bytesInRate),
+		BytesOutRate: modelRates(bytesOutRate),
+	}
+}
+
+// addThroughput sums the throughput of two sets of topics
+func addThroughput(a, b models.ThroughputStats) models.ThroughputStats {
+	return models.ThroughputStats{
+		PublishRate:  addRates(a.PublishRate, b.PublishRate),
+		DeliveryRate: addRates(a.DeliveryRate, b.DeliveryRate),
+		BytesIn:      a.BytesIn + b.BytesIn,
+		BytesOut:     a.BytesOut + b.BytesOut,
+		BytesInRate:  addRates(a.BytesInRate, b.BytesInRate),
+		BytesOutRate: addRates(a.BytesOutRate, b.BytesOutRate),
+	}
+}
+
+func modelRates(r metrics.Rates) models.Rates {
+	return models.Rates{M1: r.M1, M5: r.M5, M15: r.M15}
+}
+
+func addRates(a, b models.Rates) models.Rates {
+	return models.Rates{M1: a.M1 + b.M1, M5: a.M5 + b.M5, M15: a.M15 + b.M15}
+}
+
+// payloadSize returns the size of a payload encoded as JSON
+func payloadSize(payload interface{}) uint64 {
+	if s, ok := payload.(string); ok {
+		return uint64(len(s)) + 2 // Quotes; escaping is not counted
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0
+	}
+	return uint64(len(data))
+}
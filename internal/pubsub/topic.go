@@ -1,10 +1,16 @@
 package pubsub
 
 import (
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/tarunm/pubsub-system/internal/metrics"
 	"github.com/tarunm/pubsub-system/internal/models"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -13,13 +19,29 @@ const (
 )
 
 // Topic represents a pub/sub topic with subscribers and message history
+// A topic is split into one or more partitions, each with its own history and offsets
 type Topic struct {
-	Name          string
-	Subscribers   map[string]*Subscriber
-	MessageBuffer *RingBuffer
-	MessageCount  int64
-	CreatedAt     time.Time
-	mu            sync.RWMutex
+	Name         string
+	Tenant       string // Owning tenant ("" = default tenant)
+	Subscribers  map[string]*Subscription
+	Partitions   []*Partition
+	MessageCount int64
+	CreatedAt    time.Time
+	dedup        *DedupWindow // nil when duplicate suppression is disabled
+	roundRobin   uint64       // Next partition for messages without a key
+	mu           sync.RWMutex
+
+	// Metrics (nil when the topic was created outside an engine)
+	published      *metrics.Counter
+	delivered      *metrics.Counter
+	fanoutDuration *metrics.Histogram
+	latency        *metrics.Histogram // Publish to write, observed by subscriptions
+
+	// Throughput of publishes and of copies queued for subscribers
+	publishes  *metrics.Meter
+	deliveries *metrics.Meter
+
+	tracer trace.Tracer // The engine's tracer, or a no-op tracer
 }
 
 // NewTopic creates a new topic with the given name and default buffer size
@@ -27,37 +49,95 @@ func NewTopic(name string) *Topic {
 	return NewTopicWithBufferSize(name, DefaultBufferSize)
 }
 
-// NewTopicWithBufferSize creates a new topic with a custom buffer size
+// NewTopicWithBufferSize creates a new single-partition topic with a custom buffer size
 func NewTopicWithBufferSize(name string, bufferSize int) *Topic {
-	return &Topic{
-		Name:          name,
-		Subscribers:   make(map[string]*Subscriber),
-		MessageBuffer: NewRingBuffer(bufferSize),
-		MessageCount:  0,
-		CreatedAt:     time.Now(),
+	return NewTopicWithConfig(name, 1, bufferSize, 0, 0)
+}
+
+// NewTopicWithConfig creates a new topic with custom partition, buffer and dedup window settings
+// bufferSize applies to each partition; a dedupSize of 0 disables duplicate suppression
+func NewTopicWithConfig(name string, partitions, bufferSize int, dedupWindow time.Duration, dedupSize int) *Topic {
+	if partitions < 1 {
+		partitions = 1
 	}
+
+	topic := &Topic{
+		Name:         name,
+		Subscribers:  make(map[string]*Subscription),
+		Partitions:   make([]*Partition, partitions),
+		MessageCount: 0,
+		CreatedAt:    time.Now(),
+		publishes:    metrics.NewMeter(nil),
+		deliveries:   metrics.NewMeter(nil),
+		tracer:       noopTracer,
+	}
+	for i := range topic.Partitions {
+		topic.Partitions[i] = NewPartition(i, bufferSize)
+	}
+	if dedupSize > 0 {
+		topic.dedup = NewDedupWindow(dedupWindow, dedupSize)
+	}
+	return topic
 }
 
-// AddSubscriber adds a subscriber to the topic
+// AddSubscriber adds a subscriber to all partitions of the topic
 func (t *Topic) AddSubscriber(sub *Subscriber) {
+	t.AddSubscriberToPartitions(sub, nil)
+}
+
+// AddSubscriberToPartitions adds a subscriber to specific partitions of the topic
+// An empty partition list subscribes to all partitions; re-subscribing replaces the selection
+func (t *Topic) AddSubscriberToPartitions(sub *Subscriber, partitions []int) {
+	subscription := NewSubscription(sub, partitions)
+	subscription.delivery.latency = t.latency
+	sub.trackDelivery(t.Name, subscription.delivery)
+
+	// Register while the partitions are locked, so every message at or after the
+	// heads recorded here is fanned out to the subscription
+	var included []*Partition
+	for _, p := range t.Partitions {
+		if subscription.Includes(p.ID) {
+			included = append(included, p)
+			p.mu.Lock()
+		}
+	}
+	for _, p := range included {
+		subscription.next[p.ID] = p.NextOffset
+	}
 	t.mu.Lock()
-	defer t.mu.Unlock()
-	t.Subscribers[sub.ClientID] = sub
+	t.Subscribers[sub.ClientID] = subscription
+	t.mu.Unlock()
+	for _, p := range included {
+		p.mu.Unlock()
+	}
+
+	// Lag counts from the partition heads once the subscription receives fan-outs
+	for _, p := range included {
+		subscription.delivery.begin(p.ID, subscription.next[p.ID])
+	}
 }
 
 // RemoveSubscriber removes a subscriber from the topic
 func (t *Topic) RemoveSubscriber(clientID string) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
+	subscription, exists := t.Subscribers[clientID]
 	delete(t.Subscribers, clientID)
+	t.mu.Unlock()
+
+	if exists {
+		subscription.Subscriber.untrackDelivery(t.Name, subscription.delivery)
+	}
 }
 
 // GetSubscriber returns a specific subscriber by client ID
 func (t *Topic) GetSubscriber(clientID string) (*Subscriber, bool) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	sub, exists := t.Subscribers[clientID]
-	return sub, exists
+	subscription, exists := t.Subscribers[clientID]
+	if !exists {
+		return nil, false
+	}
+	return subscription.Subscriber, true
 }
 
 // GetSubscribers returns a slice of all subscribers
@@ -66,8 +146,20 @@ func (t *Topic) GetSubscribers() []*Subscriber {
 	defer t.mu.RUnlock()
 
 	subs := make([]*Subscriber, 0, len(t.Subscribers))
-	for _, sub := range t.Subscribers {
-		subs = append(subs, sub)
+	for _, subscription := range t.Subscribers {
+		subs = append(subs, subscription.Subscriber)
+	}
+	return subs
+}
+
+// getSubscriptions returns a slice of all subscriptions
+func (t *Topic) getSubscriptions() []*Subscription {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	subs := make([]*Subscription, 0, len(t.Subscribers))
+	for _, subscription := range t.Subscribers {
+		subs = append(subs, subscription)
 	}
 	return subs
 }
@@ -79,16 +171,36 @@ func (t *Topic) GetSubscriberCount() int {
 	return len(t.Subscribers)
 }
 
-// PublishMessage publishes a message to all subscribers and stores it in history
+// PublishMessage stores a message in its partition's history and fans it out
+// Messages are routed by ordering key hash, or round-robin when no key is set.
+// The partition is locked only to assign the offset and store the message; fan-outs
+// run concurrently and each subscription enqueues a partition's messages (and
+// therefore a key's messages) in offset order
 func (t *Topic) PublishMessage(msg models.Message) {
-	// Store message in buffer and increment count
+	start := time.Now()
+	partition := t.route(msg.Key)
+
+	// Assign the partition offset and store message in history
+	partition.mu.Lock()
+	msg.Partition = partition.ID
+	msg.Offset = partition.NextOffset
+	partition.NextOffset++
+
+	span := startMessageSpan(t.tracer, "fanout "+t.Name, trace.SpanKindInternal, &msg,
+		semconv.MessagingDestinationName(t.Name), semconv.MessagingDestinationPartitionID(strconv.Itoa(partition.ID)))
+	defer span.End()
+	partition.Buffer.Add(msg)
+	partition.mu.Unlock()
+
 	t.mu.Lock()
-	t.MessageBuffer.Add(msg)
 	t.MessageCount++
 	t.mu.Unlock()
+	t.published.Inc()
+	size := payloadSize(msg.Payload)
+	t.publishes.Mark(1, size)
 
-	// Fan-out to all subscribers
-	subscribers := t.GetSubscribers()
+	// Fan-out to subscribers of this partition
+	subscriptions := t.getSubscriptions()
 
 	serverMsg := models.ServerMessage{
 		Type:      "event",
@@ -97,17 +209,120 @@ func (t *Topic) PublishMessage(msg models.Message) {
 		Timestamp: msg.Timestamp.UTC().Format(time.RFC3339),
 	}
 
-	for _, sub := range subscribers {
-		// Skip closed subscribers
-		if !sub.IsClosed() {
-			sub.SendMessage(serverMsg)
+	// Events held back for ordering are enqueued by whichever fan-out releases them
+	var queued, queuedBytes uint64
+	for _, subscription := range subscriptions {
+		if !subscription.Includes(partition.ID) {
+			continue
 		}
+		subscriber := subscription.Subscriber
+		subscription.deliver(fanoutEvent{serverMsg, size}, func(event fanoutEvent) {
+			// Skip closed subscribers
+			if !subscriber.IsClosed() && subscriber.enqueue(event.message) {
+				t.delivered.Inc()
+				queued++
+				queuedBytes += event.size
+			}
+		})
+	}
+	t.deliveries.Mark(queued, queuedBytes)
+	t.fanoutDuration.Observe(time.Since(start).Seconds())
+}
+
+// route selects the partition for a message
+func (t *Topic) route(key string) *Partition {
+	n := len(t.Partitions)
+	if n == 1 {
+		return t.Partitions[0]
+	}
+	if key != "" {
+		return t.Partitions[PartitionForKey(key, n)]
 	}
+	next := atomic.AddUint64(&t.roundRobin, 1) - 1
+	return t.Partitions[next%uint64(n)]
 }
 
-// GetLastN retrieves the last n messages from the topic's history
+// GetLastN retrieves the last n messages from the topic's history across all partitions
 func (t *Topic) GetLastN(n int) []models.Message {
-	return t.MessageBuffer.GetLast(n)
+	return t.GetLastNFromPartitions(n, nil)
+}
+
+// GetLastNFromPartitions retrieves the last n messages from the selected partitions
+// Messages are returned in chronological order; an empty selection means all partitions
+func (t *Topic) GetLastNFromPartitions(n int, partitions []int) []models.Message {
+	if len(partitions) == 0 {
+		partitions = make([]int, len(t.Partitions))
+		for i := range partitions {
+			partitions[i] = i
+		}
+	}
+
+	if len(partitions) == 1 {
+		return t.Partitions[partitions[0]].Buffer.GetLast(n)
+	}
+
+	histories := make([][]models.Message, 0, len(partitions))
+	for _, id := range partitions {
+		histories = append(histories, t.Partitions[id].Buffer.GetLast(n))
+	}
+
+	merged := mergeByTimestamp(histories)
+	if len(merged) > n {
+		merged = merged[len(merged)-n:]
+	}
+	return merged
+}
+
+// GetPartitionCount returns the number of partitions
+func (t *Topic) GetPartitionCount() int {
+	return len(t.Partitions)
+}
+
+// ValidPartition reports whether id refers to a partition of this topic
+func (t *Topic) ValidPartition(id int) bool {
+	return id >= 0 && id < len(t.Partitions)
+}
+
+// GetPartitionStats returns per-partition offsets and history sizes
+func (t *Topic) GetPartitionStats() []models.PartitionStats {
+	stats := make([]models.PartitionStats, len(t.Partitions))
+	for i, p := range t.Partitions {
+		stats[i] = models.PartitionStats{
+			ID:       p.ID,
+			Offset:   p.GetNextOffset(),
+			Buffered: p.Buffer.Size(),
+		}
+	}
+	return stats
+}
+
+// GetSubscriptionStats returns delivery statistics for each subscriber, sorted by client ID
+func (t *Topic) GetSubscriptionStats() []models.SubscriptionStats {
+	subscriptions := t.getSubscriptions()
+
+	stats := make([]models.SubscriptionStats, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		stats = append(stats, subscription.Stats(t.Partitions))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ClientID < stats[j].ClientID
+	})
+	return stats
+}
+
+// GetThroughput returns the topic's publish and delivery rates and payload bytes
+func (t *Topic) GetThroughput() models.ThroughputStats {
+	return throughputStats(t.publishes, t.deliveries)
+}
+
+// GetLatency returns percentiles of the time from publish until events are written to subscribers
+func (t *Topic) GetLatency() models.LatencyStats {
+	return models.LatencyStats{
+		Count: t.latency.Count(),
+		P50:   t.latency.Quantile(0.50) * 1000,
+		P95:   t.latency.Quantile(0.95) * 1000,
+		P99:   t.latency.Quantile(0.99) * 1000,
+	}
 }
 
 // GetMessageCount returns the total number of messages published to this topic
@@ -116,3 +331,27 @@ func (t *Topic) GetMessageCount() int64 {
 	defer t.mu.RUnlock()
 	return t.MessageCount
 }
+
+// IsDuplicate reports whether a message ID was already published within the dedup window
+// Unseen IDs are recorded, so a second call with the same ID returns true
+func (t *Topic) IsDuplicate(messageID string) bool {
+	if t.dedup == nil {
+		return false
+	}
+	return t.dedup.IsDuplicate(messageID)
+}
+
+// ForgetMessageID removes a message ID from the dedup window so it may be published again
+func (t *Topic) ForgetMessageID(messageID string) {
+	if t.dedup != nil {
+		t.dedup.Forget(messageID)
+	}
+}
+
+// GetDedupHits returns the number of duplicate publishes suppressed for this topic
+func (t *Topic) GetDedupHits() int64 {
+	if t.dedup == nil {
+		return 0
+	}
+	return t.dedup.Hits()
+}
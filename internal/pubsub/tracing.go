@@ -0,0 +1,52 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName identifies spans created by the engine, its topics and subscribers
+const tracerName = "github.com/tarunm/pubsub-system/internal/pubsub"
+
+var (
+	// noopTracer is used by topics and subscribers created outside an engine
+	noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+	// messagingSystem is set on every span
+	messagingSystem = semconv.MessagingSystemKey.String("pubsub-system")
+
+	// Span attributes specific to this system
+	attrDuplicate = attribute.Key("pubsub.duplicate")
+	attrScheduled = attribute.Key("pubsub.scheduled")
+	attrDropped   = attribute.Key("pubsub.dropped")
+)
+
+// startMessageSpan starts a span continuing the trace referenced by msg's headers
+// msg's headers are then pointed at the new span, so the next stage and finally the
+// consuming client continue the trace from it. msg must not be shared; the header map is copied
+func startMessageSpan(tracer trace.Tracer, name string, kind trace.SpanKind, msg *models.Message, attrs ...attribute.KeyValue) trace.Span {
+	parent := tracing.Extract(context.Background(), msg.Headers)
+	ctx, span := tracer.Start(parent, name,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(messagingSystem, semconv.MessagingMessageID(msg.ID)),
+		trace.WithAttributes(attrs...))
+
+	// A no-op tracer returns the parent's span, which needs no new header
+	if !span.SpanContext().Equal(trace.SpanContextFromContext(parent)) {
+		msg.Headers = tracing.Inject(ctx, msg.Headers)
+	}
+	return span
+}
+
+// recordError marks span as failed with err
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a thread-safe token bucket rate limiter
+// Tokens refill continuously at rate per second up to burst
+type TokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewTokenBucket creates a full bucket refilling at rate tokens per second
+// A burst below 1 is raised to max(rate, 1)
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := float64(burst)
+	if b < 1 {
+		b = rate
+		if b < 1 {
+			b = 1
+		}
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// Allow takes one token if available
+func (b *TokenBucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+// AllowN takes n tokens if available
+// Requests larger than the burst only need a full bucket, and empty it
+func (b *TokenBucket) AllowN(n float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	n = b.clamp(n)
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// Delay returns how long until n tokens are available, without taking them
+func (b *TokenBucket) Delay(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	missing := b.clamp(n) - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.rate * float64(time.Second))
+}
+
+// Full reports whether the bucket has refilled completely
+func (b *TokenBucket) Full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	return b.tokens >= b.burst
+}
+
+// carryFrom sets b's tokens to the level old has now, capped at b's burst
+// Does nothing when either bucket is nil
+func (b *TokenBucket) carryFrom(old *TokenBucket) {
+	if b == nil || old == nil {
+		return
+	}
+
+	old.mu.Lock()
+	old.refill(time.Now())
+	tokens := old.tokens
+	old.mu.Unlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = b.clamp(tokens)
+}
+
+// clamp caps a request at the burst so oversized requests can still succeed
+func (b *TokenBucket) clamp(n float64) float64 {
+	if n > b.burst {
+		return b.burst
+	}
+	return n
+}
+
+// refill adds tokens accrued since the last call
+// Must be called with the lock held
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import "sync"
+
+// maxIdleBuckets is how many buckets a KeyedBuckets holds before idle ones are dropped
+const maxIdleBuckets = 10000
+
+// KeyedBuckets lazily creates one token bucket per key, all with the same rate
+type KeyedBuckets struct {
+	rate    float64
+	burst   int
+	buckets map[string]*TokenBucket
+	mu      sync.Mutex
+}
+
+// NewKeyedBuckets creates per-key buckets refilling at rate tokens per second
+// A rate of zero or less disables the limit and returns nil
+func NewKeyedBuckets(rate float64, burst int) *KeyedBuckets {
+	if rate <= 0 {
+		return nil
+	}
+	return &KeyedBuckets{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*TokenBucket),
+	}
+}
+
+// Get returns the bucket for key, creating it if needed
+// Returns nil when k is nil
+func (k *KeyedBuckets) Get(key string) *TokenBucket {
+	if k == nil {
+		return nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	bucket, exists := k.buckets[key]
+	if !exists {
+		if len(k.buckets) >= maxIdleBuckets {
+			k.prune()
+		}
+		bucket = NewTokenBucket(k.rate, k.burst)
+		k.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// carryFrom creates a bucket for each key old is limiting, starting at its current level
+// Keys with full buckets are skipped, as are all keys when either k or old is nil
+func (k *KeyedBuckets) carryFrom(old *KeyedBuckets) {
+	if k == nil || old == nil {
+		return
+	}
+
+	old.mu.Lock()
+	defer old.mu.Unlock()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for key, bucket := range old.buckets {
+		if bucket.Full() {
+			continue
+		}
+		next := NewTokenBucket(k.rate, k.burst)
+		next.carryFrom(bucket)
+		k.buckets[key] = next
+	}
+}
+
+// prune drops full buckets, which behave the same as freshly created ones
+// Must be called with the lock held
+func (k *KeyedBuckets) prune() {
+	for key, bucket := range k.buckets {
+		if bucket.Full() {
+			delete(k.buckets, key)
+		}
+	}
+}
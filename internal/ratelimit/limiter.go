@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Rule limits messages and payload bytes per second. Zero disables either limit.
+// Bursts of up to one second's worth are allowed.
+type Rule struct {
+	Messages float64
+	Bytes    float64
+}
+
+// Config configures a Limiter. Zero values mean unlimited.
+type Config struct {
+	PublishGlobal   Rule    // Across all clients
+	PublishPerKey   Rule    // Per API key, token subject or unauthenticated client
+	PublishPerTopic Rule    // Per topic
+	ConnectGlobal   float64 // WebSocket connection attempts per second across all clients
+	ConnectPerIP    float64 // WebSocket connection attempts per second per client IP
+}
+
+// Limiter enforces publish and connection attempt rate limits
+// Limits can be replaced at runtime with Update
+type Limiter struct {
+	buckets atomic.Pointer[buckets]
+}
+
+// buckets holds the token buckets for one Config; nil buckets are unlimited
+type buckets struct {
+	cfg            Config
+	mu             sync.Mutex // Held across a take's check and take
+	globalMessages *TokenBucket
+	globalBytes    *TokenBucket
+	keyMessages    *KeyedBuckets
+	keyBytes       *KeyedBuckets
+	topicMessages  *KeyedBuckets
+	topicBytes     *KeyedBuckets
+	connectGlobal  *TokenBucket
+	connectPerIP   *KeyedBuckets
+}
+
+// request is a number of tokens to take from a bucket
+type request struct {
+	bucket *TokenBucket
+	n      float64
+}
+
+// New creates a limiter for cfg
+// A limiter with no limits configured allows everything
+func New(cfg Config) *Limiter {
+	l := &Limiter{}
+	l.Update(cfg)
+	return l
+}
+
+// Update replaces the limits with cfg
+// Unchanged limits keep their buckets. Changed limits start from the tokens left
+// under the old limits, capped at the new burst, so a reload cannot refill them
+func (l *Limiter) Update(cfg Config) {
+	old := l.buckets.Load()
+	if (old == nil && cfg == (Config{})) || (old != nil && old.cfg == cfg) {
+		return
+	}
+
+	next := newBuckets(cfg)
+	if next != nil && old != nil {
+		next.carryFrom(old)
+	}
+	l.buckets.Store(next)
+}
+
+// newBuckets creates the buckets for cfg, or returns nil when no limits are configured
+func newBuckets(cfg Config) *buckets {
+	if cfg == (Config{}) {
+		return nil
+	}
+
+	return &buckets{
+		cfg:            cfg,
+		globalMessages: newBucket(cfg.PublishGlobal.Messages),
+		globalBytes:    newBucket(cfg.PublishGlobal.Bytes),
+		keyMessages:    NewKeyedBuckets(cfg.PublishPerKey.Messages, 0),
+		keyBytes:       NewKeyedBuckets(cfg.PublishPerKey.Bytes, 0),
+		topicMessages:  NewKeyedBuckets(cfg.PublishPerTopic.Messages, 0),
+		topicBytes:     NewKeyedBuckets(cfg.PublishPerTopic.Bytes, 0),
+		connectGlobal:  newBucket(cfg.ConnectGlobal),
+		connectPerIP:   NewKeyedBuckets(cfg.ConnectPerIP, 0),
+	}
+}
+
+// carryFrom copies the token levels of old into b
+// Holds old's lock so takes on old cannot interleave with the copy
+func (b *buckets) carryFrom(old *buckets) {
+	old.mu.Lock()
+	defer old.mu.Unlock()
+
+	b.globalMessages.carryFrom(old.globalMessages)
+	b.globalBytes.carryFrom(old.globalBytes)
+	b.keyMessages.carryFrom(old.keyMessages)
+	b.keyBytes.carryFrom(old.keyBytes)
+	b.topicMessages.carryFrom(old.topicMessages)
+	b.topicBytes.carryFrom(old.topicBytes)
+	b.connectGlobal.carryFrom(old.connectGlobal)
+	b.connectPerIP.carryFrom(old.connectPerIP)
+}
+
+// CountsBytes reports whether publishes are limited by payload size
+// Callers can skip measuring payloads when it returns false
+func (l *Limiter) CountsBytes() bool {
+	b := l.load()
+	return b != nil && (b.globalBytes != nil || b.keyBytes != nil || b.topicBytes != nil)
+}
+
+// AllowPublish reports whether a publish of size payload bytes by key to topic is within limits
+// When it is not, it also returns how long to wait before retrying
+func (l *Limiter) AllowPublish(key, topic string, size int) (bool, time.Duration) {
+	b := l.load()
+	if b == nil {
+		return true, 0
+	}
+
+	bytes := float64(size)
+	return b.take(
+		request{b.globalMessages, 1},
+		request{b.globalBytes, bytes},
+		request{b.keyMessages.Get(key), 1},
+		request{b.keyBytes.Get(key), bytes},
+		request{b.topicMessages.Get(topic), 1},
+		request{b.topicBytes.Get(topic), bytes},
+	)
+}
+
+// AllowConnect reports whether a connection attempt from ip is within limits
+// When it is not, it also returns how long to wait before retrying
+func (l *Limiter) AllowConnect(ip string) (bool, time.Duration) {
+	b := l.load()
+	if b == nil {
+		return true, 0
+	}
+
+	return b.take(
+		request{b.connectGlobal, 1},
+		request{b.connectPerIP.Get(ip), 1},
+	)
+}
+
+// load returns the current buckets, or nil when l is nil or has no limits
+func (l *Limiter) load() *buckets {
+	if l == nil {
+		return nil
+	}
+	return l.buckets.Load()
+}
+
+// take takes tokens from every bucket only if all of them have enough
+// Otherwise it returns the longest wait among the buckets that are short.
+// Buckets are only drawn from here, so holding b.mu keeps the check valid until the take
+func (b *buckets) take(requests ...request) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var wait time.Duration
+	for _, r := range requests {
+		if r.bucket == nil {
+			continue
+		}
+		if d := r.bucket.Delay(r.n); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+
+	for _, r := range requests {
+		if r.bucket != nil {
+			r.bucket.AllowN(r.n)
+		}
+	}
+	return true, 0
+}
+
+// newBucket creates a bucket with a one second burst, or nil when rate is not positive
+func newBucket(rate float64) *TokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return NewTokenBucket(rate, 0)
+}
@@ -0,0 +1,169 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/tarunm/pubsub-system/internal/ratelimit"
+)
+
+var (
+	// ErrConnectionLimit is returned when a tenant has reached its connection limit
+	ErrConnectionLimit = errors.New("tenant connection limit reached")
+
+	// validName restricts tenant names so they can safely prefix topic keys
+	validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Limits caps what a tenant may use. Zero values mean unlimited.
+type Limits struct {
+	MaxTopics       int     `json:"max_topics"`
+	MaxConnections  int     `json:"max_connections"`
+	MaxPublishRate  float64 `json:"max_publish_rate"`  // Messages per second
+	MaxPublishBurst int     `json:"max_publish_burst"` // Defaults to max_publish_rate
+}
+
+// Tenant is a namespace that API keys belong to
+type Tenant struct {
+	Name    string   `json:"name"`
+	APIKeys []string `json:"api_keys"`
+	Limits  Limits   `json:"limits"`
+}
+
+// Config is the on-disk format of a tenants file
+type Config struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Registry tracks tenants, their limits and their current usage
+type Registry struct {
+	tenants     map[string]*Tenant
+	connections map[string]int
+	limiters    map[string]*ratelimit.TokenBucket
+	mu          sync.Mutex
+}
+
+// ValidName reports whether name can be used as a tenant name
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// NewRegistry creates a registry from a tenants config
+func NewRegistry(cfg Config) (*Registry, error) {
+	r := &Registry{
+		tenants:     make(map[string]*Tenant),
+		connections: make(map[string]int),
+		limiters:    make(map[string]*ratelimit.TokenBucket),
+	}
+
+	seenKeys := make(map[string]string)
+	for i := range cfg.Tenants {
+		t := &cfg.Tenants[i]
+		if !ValidName(t.Name) {
+			return nil, fmt.Errorf("tenant %d: invalid name %q", i, t.Name)
+		}
+		if _, exists := r.tenants[t.Name]; exists {
+			return nil, fmt.Errorf("tenant %q defined twice", t.Name)
+		}
+		for _, key := range t.APIKeys {
+			if owner, exists := seenKeys[key]; exists {
+				return nil, fmt.Errorf("tenant %q: API key already belongs to tenant %q", t.Name, owner)
+			}
+			seenKeys[key] = t.Name
+		}
+
+		r.tenants[t.Name] = t
+		if t.Limits.MaxPublishRate > 0 {
+			r.limiters[t.Name] = ratelimit.NewTokenBucket(t.Limits.MaxPublishRate, t.Limits.MaxPublishBurst)
+		}
+	}
+	return r, nil
+}
+
+// LoadRegistry reads tenants from a JSON file
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tenants file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse tenants file: %w", err)
+	}
+	return NewRegistry(cfg)
+}
+
+// Tenants returns all configured tenants
+func (r *Registry) Tenants() []Tenant {
+	if r == nil {
+		return nil
+	}
+
+	tenants := make([]Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, *t)
+	}
+	return tenants
+}
+
+// MaxTopics returns the topic limit for a tenant (0 = unlimited)
+func (r *Registry) MaxTopics(tenant string) int {
+	if r == nil {
+		return 0
+	}
+	if t, exists := r.tenants[tenant]; exists {
+		return t.Limits.MaxTopics
+	}
+	return 0
+}
+
+// AcquireConnection reserves a connection slot for a tenant
+// Every successful call must be paired with ReleaseConnection
+func (r *Registry) AcquireConnection(tenant string) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, exists := r.tenants[tenant]; exists && t.Limits.MaxConnections > 0 {
+		if r.connections[tenant] >= t.Limits.MaxConnections {
+			return ErrConnectionLimit
+		}
+	}
+	r.connections[tenant]++
+	return nil
+}
+
+// ReleaseConnection frees a connection slot reserved by AcquireConnection
+func (r *Registry) ReleaseConnection(tenant string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.connections[tenant] > 0 {
+		r.connections[tenant]--
+	}
+}
+
+// AllowPublish reports whether a tenant is within its publish rate
+func (r *Registry) AllowPublish(tenant string) bool {
+	if r == nil {
+		return true
+	}
+
+	limiter, exists := r.limiters[tenant]
+	if !exists {
+		return true
+	}
+	return limiter.Allow()
+}
@@ -0,0 +1,234 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Client certificate verification modes
+const (
+	ClientAuthNone     = "none"     // Client certificates are not requested
+	ClientAuthOptional = "optional" // Verified when presented, but not required
+	ClientAuthRequire  = "require"  // Every client must present a valid certificate
+)
+
+// Options configures the server's TLS listener
+type Options struct {
+	CertFile     string   // PEM certificate chain
+	KeyFile      string   // PEM private key
+	MinVersion   string   // "1.2" or "1.3" (default "1.2")
+	CipherSuites []string // TLS 1.2 cipher suite names (empty = Go defaults)
+	ClientCAFile string   // PEM CA bundle for verifying client certificates (empty = no client certs)
+	ClientAuth   string   // ClientAuthOptional or ClientAuthRequire (default require when ClientCAFile is set)
+}
+
+// Reloader serves TLS with certificates that can be replaced without a restart
+// New handshakes use the latest certificate and client CA bundle; open connections are unaffected
+type Reloader struct {
+	opts         Options
+	minVersion   uint16
+	cipherSuites []uint16
+	clientAuth   tls.ClientAuthType
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+
+	modTimes map[string]time.Time // Last seen modification time per file
+	mu       sync.Mutex           // Serializes reloads
+}
+
+// NewReloader validates opts and loads the initial certificates
+func NewReloader(opts Options) (*Reloader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("both a certificate and a key file are required")
+	}
+
+	minVersion, err := parseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := parseClientAuth(opts.ClientAuth, opts.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{
+		opts:         opts,
+		minVersion:   minVersion,
+		cipherSuites: cipherSuites,
+		clientAuth:   clientAuth,
+		modTimes:     make(map[string]time.Time),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Config returns a server TLS configuration that always uses the latest certificates
+// Each handshake uses a copy of the returned configuration with the current certificates,
+// so fields set on it before serving still apply. http.Server only adds its ALPN protocols
+// to its own copy, so NextProtos starts with the protocols it serves by default
+func (r *Reloader) Config() *tls.Config {
+	base := &tls.Config{
+		MinVersion:   r.minVersion,
+		CipherSuites: r.cipherSuites,
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*r.cert.Load()}
+		cfg.ClientCAs = r.clientCAs.Load()
+		return cfg, nil
+	}
+	return base
+}
+
+// Reload reads the certificate, key and client CA files again
+// On error the previously loaded certificates stay in use
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		data, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("client CA file %s contains no certificates", r.opts.ClientCAFile)
+		}
+	}
+
+	r.cert.Store(&cert)
+	r.clientCAs.Store(pool)
+	for _, path := range r.files() {
+		if info, err := os.Stat(path); err == nil {
+			r.modTimes[path] = info.ModTime()
+		}
+	}
+	return nil
+}
+
+// Watch reloads the certificates whenever one of the files changes, until stop is closed
+// Files are polled every interval
+func (r *Reloader) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.Error("TLS certificate reload failed, keeping previous certificates", "error", err)
+			} else {
+				slog.Info("TLS certificates reloaded")
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// changed reports whether any file was modified since the last reload
+func (r *Reloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Possibly mid-replacement; check again next tick
+		}
+		if !info.ModTime().Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns the paths the reloader reads
+func (r *Reloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+// parseVersion converts a minimum version setting into a TLS version constant
+func parseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported minimum TLS version %q (use 1.2 or 1.3)", version)
+	}
+}
+
+// parseCipherSuites converts cipher suite names into IDs, rejecting insecure suites
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseClientAuth converts a client auth setting into a verification mode
+func parseClientAuth(mode, clientCAFile string) (tls.ClientAuthType, error) {
+	if clientCAFile == "" {
+		if mode != "" && mode != ClientAuthNone {
+			return 0, fmt.Errorf("client auth %q requires a client CA file", mode)
+		}
+		return tls.NoClientCert, nil
+	}
+
+	switch mode {
+	case "", ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	default:
+		return 0, fmt.Errorf("unsupported client auth mode %q (use %s or %s)", mode, ClientAuthOptional, ClientAuthRequire)
+	}
+}
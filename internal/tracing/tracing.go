@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Span exporters
+const (
+	ExporterNone = ""     // Tracing disabled
+	ExporterOTLP = "otlp" // OTLP over HTTP to a collector
+	ExporterFile = "file" // One JSON span per line, for local debugging and tests
+)
+
+// Options configures span export
+type Options struct {
+	Exporter     string  // ExporterOTLP, ExporterFile or ExporterNone
+	OTLPEndpoint string  // host:port of the OTLP/HTTP receiver (empty = OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318)
+	OTLPInsecure bool    // Send OTLP over plain HTTP
+	File         string  // Output file for ExporterFile
+	ServiceName  string  // service.name resource attribute
+	SampleRatio  float64 // Fraction of new traces sampled (0-1); traces started by a publisher follow its decision
+}
+
+// Provider creates tracers that export spans as configured
+// A nil provider creates no-op tracers
+type Provider struct {
+	tp   *sdktrace.TracerProvider
+	file *os.File // Output of the file exporter
+}
+
+// Open creates a provider for opts, or returns nil when tracing is disabled
+func Open(opts Options) (*Provider, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		file     *os.File
+		err      error
+	)
+	switch opts.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{}
+		if opts.OTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.OTLPEndpoint))
+		}
+		if opts.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), clientOpts...)
+	case ExporterFile:
+		if opts.File == "" {
+			return nil, fmt.Errorf("a trace file is required for the %s exporter", ExporterFile)
+		}
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (expected %s or %s)", opts.Exporter, ExporterOTLP, ExporterFile)
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	ratio := min(max(opts.SampleRatio, 0), 1)
+	tpOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(opts.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	}
+	if file != nil {
+		// Write each span as it ends so the file can be tailed
+		tpOpts = append(tpOpts, sdktrace.WithSyncer(exporter))
+	} else {
+		tpOpts = append(tpOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	return &Provider{tp: sdktrace.NewTracerProvider(tpOpts...), file: file}, nil
+}
+
+// TracerProvider returns the provider to create tracers from
+func (p *Provider) TracerProvider() trace.TracerProvider {
+	if p == nil {
+		return noop.NewTracerProvider()
+	}
+	return p.tp
+}
+
+// Shutdown flushes pending spans and stops the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	err := p.tp.Shutdown(ctx)
+	if p.file != nil {
+		if closeErr := p.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// propagator reads and writes the W3C traceparent and tracestate headers
+var propagator = propagation.TraceContext{}
+
+// Extract returns ctx continuing the trace referenced by headers, if any
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// Inject returns a copy of headers referencing the span in ctx
+// headers is returned unchanged when ctx has no valid span
+func Inject(ctx context.Context, headers map[string]string) map[string]string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return headers
+	}
+
+	injected := make(map[string]string, len(headers)+2)
+	for k, v := range headers {
+		injected[k] = v
+	}
+	propagator.Inject(ctx, propagation.MapCarrier(injected))
+	return injected
+}
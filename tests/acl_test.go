@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// testACLPolicy grants full access to admins, publish-only access to producers,
+// subscribe-only access to consumers and read access to "public" for everyone
+var testACLPolicy = auth.ACLPolicy{
+	Rules: []auth.ACLRule{
+		{Principals: []string{"admin-key"}, Topics: []string{"*"}, Actions: []string{"*"}},
+		{Principals: []string{"producer-key"}, Topics: []string{"orders.*"}, Actions: []string{auth.ActionPublish}},
+		{Principals: []string{"consumer-key"}, Topics: []string{"orders.*"}, Actions: []string{auth.ActionSubscribe}},
+		{Principals: []string{"*"}, Topics: []string{"public"}, Actions: []string{auth.ActionSubscribe}},
+	},
+}
+
+var testACLKeys = []string{"admin-key", "producer-key", "consumer-key"}
+
+// TestACL_REST_CreateDelete tests that topic creation and deletion follow the policy
+func TestACL_REST_CreateDelete(t *testing.T) {
+	server, cleanup := SetupTestServerWithACL(t, testACLKeys, writeACLPolicy(t, testACLPolicy))
+	defer cleanup()
+
+	resp := CreateTopicWithAuth(t, server.URL, "orders.created", "producer-key")
+	expectRESTForbidden(t, resp)
+
+	resp = CreateTopicWithAuth(t, server.URL, "orders.created", "admin-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 for admin, got %d", resp.StatusCode)
+	}
+
+	resp = deleteTopicWithAuth(t, server.URL, "orders.created", "consumer-key")
+	expectRESTForbidden(t, resp)
+
+	resp = deleteTopicWithAuth(t, server.URL, "orders.created", "admin-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 for admin delete, got %d", resp.StatusCode)
+	}
+}
+
+// TestACL_WebSocket_PublishSubscribe tests that publish and subscribe follow the policy
+func TestACL_WebSocket_PublishSubscribe(t *testing.T) {
+	server, cleanup := SetupTestServerWithACL(t, testACLKeys, writeACLPolicy(t, testACLPolicy))
+	defer cleanup()
+
+	for _, topic := range []string{"orders.created", "payments", "public"} {
+		CreateTopicWithAuth(t, server.URL, topic, "admin-key").Body.Close()
+	}
+
+	producer := ConnectWebSocket(t, server.WSURL, "producer")
+	defer producer.Close()
+	authenticateWithKey(t, producer, "producer-key")
+
+	consumer := ConnectWebSocket(t, server.WSURL, "consumer")
+	defer consumer.Close()
+	authenticateWithKey(t, consumer, "consumer-key")
+
+	// Consumer may subscribe to orders.* and public, but not publish
+	Subscribe(t, consumer, "orders.created", 0, "sub-1")
+	if msg := WaitForAck(t, consumer, "sub-1", 2*time.Second); msg.Status != "ok" {
+		t.Fatalf("Expected consumer subscribe to succeed, got %s", msg.Status)
+	}
+	Subscribe(t, consumer, "public", 0, "sub-2")
+	if msg := WaitForAck(t, consumer, "sub-2", 2*time.Second); msg.Status != "ok" {
+		t.Fatalf("Expected wildcard principal rule to allow subscribe, got %s", msg.Status)
+	}
+	Subscribe(t, consumer, "payments", 0, "sub-3")
+	expectForbidden(t, consumer, "sub-3")
+	Publish(t, consumer, "orders.created", uuid.NewString(), "denied", "pub-1")
+	expectForbidden(t, consumer, "pub-1")
+
+	// Producer may publish to orders.* only, and the consumer receives it
+	Publish(t, producer, "orders.created", uuid.NewString(), "allowed", "pub-2")
+	if msg := WaitForAck(t, producer, "pub-2", 2*time.Second); msg.Status != "ok" {
+		t.Fatalf("Expected producer publish to succeed, got %s", msg.Status)
+	}
+	event := WaitForEvent(t, consumer, 2*time.Second)
+	if event.Topic != "orders.created" {
+		t.Errorf("Expected event on orders.created, got %s", event.Topic)
+	}
+
+	Publish(t, producer, "payments", uuid.NewString(), "denied", "pub-3")
+	expectForbidden(t, producer, "pub-3")
+	Subscribe(t, producer, "orders.created", 0, "sub-4")
+	expectForbidden(t, producer, "sub-4")
+}
+
+// TestACL_REST_Scheduled tests that scheduled messages are listed and cancelled only with publish rights
+func TestACL_REST_Scheduled(t *testing.T) {
+	server, cleanup := SetupTestServerWithACL(t, testACLKeys, writeACLPolicy(t, testACLPolicy))
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders.created", "admin-key").Body.Close()
+
+	producer := ConnectWebSocket(t, server.WSURL, "producer")
+	defer producer.Close()
+	authenticateWithKey(t, producer, "producer-key")
+
+	messageID := uuid.NewString()
+	PublishDelayed(t, producer, "orders.created", messageID, "later", 60000, "pub-1")
+	if msg := WaitForAck(t, producer, "pub-1", 2*time.Second); msg.Status != "scheduled" {
+		t.Fatalf("Expected producer to schedule, got %s", msg.Status)
+	}
+
+	// The consumer may subscribe to the topic but not publish to it
+	if scheduled := listScheduledWithAuth(t, server.URL, "", "consumer-key"); len(scheduled) != 0 {
+		t.Errorf("Expected consumer to see no scheduled messages, got %d", len(scheduled))
+	}
+	expectRESTForbidden(t, makeGetRequest(t, server.URL+"/scheduled?topic=orders.created", "consumer-key"))
+	expectRESTForbidden(t, cancelScheduledWithAuth(t, server.URL, messageID, "consumer-key"))
+
+	if scheduled := listScheduledWithAuth(t, server.URL, "orders.created", "producer-key"); len(scheduled) != 1 {
+		t.Errorf("Expected producer to see 1 scheduled message, got %d", len(scheduled))
+	}
+	resp := cancelScheduledWithAuth(t, server.URL, messageID, "producer-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 for producer cancel, got %d", resp.StatusCode)
+	}
+}
+
+// TestACL_TenantScopedTopics tests that rule topics match tenant-scoped names
+func TestACL_TenantScopedTopics(t *testing.T) {
+	policy := auth.ACLPolicy{Rules: []auth.ACLRule{
+		{Principals: []string{"*"}, Topics: []string{"alpha/*"}, Actions: []string{"*"}},
+		{Principals: []string{"*"}, Topics: []string{"orders"}, Actions: []string{auth.ActionCreate}},
+	}}
+	server, cleanup := SetupTestServerWithConfig(t, []string{"default-key"}, func(cfg *config.Config) {
+		cfg.ACLFile = writeACLPolicy(t, policy)
+		cfg.TenantsFile = writeTenants(t, testTenants)
+	})
+	defer cleanup()
+
+	for _, tc := range []struct {
+		key    string
+		status int
+	}{
+		{"alpha-key", http.StatusCreated},
+		{"default-key", http.StatusCreated},
+		{"beta-key", http.StatusForbidden}, // "orders" only matches the default tenant's topic
+	} {
+		resp := CreateTopicWithAuth(t, server.URL, "orders", tc.key)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("Expected status %d creating orders with %s, got %d", tc.status, tc.key, resp.StatusCode)
+		}
+	}
+}
+
+// TestACL_InvalidPolicy tests that malformed policies are rejected at load time
+func TestACL_InvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy auth.ACLPolicy
+	}{
+		{
+			name: "unknown action",
+			policy: auth.ACLPolicy{Rules: []auth.ACLRule{
+				{Principals: []string{"*"}, Topics: []string{"*"}, Actions: []string{"admin"}},
+			}},
+		},
+		{
+			name: "missing topics",
+			policy: auth.ACLPolicy{Rules: []auth.ACLRule{
+				{Principals: []string{"*"}, Actions: []string{auth.ActionPublish}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.LoadACL(writeACLPolicy(t, tt.policy)); err == nil {
+				t.Error("Expected policy to be rejected")
+			}
+		})
+	}
+}
+
+// Helper functions for ACL tests
+
+func writeACLPolicy(t *testing.T, policy auth.ACLPolicy) string {
+	t.Helper()
+
+	data, _ := json.Marshal(policy)
+	path := filepath.Join(t.TempDir(), "acl.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write ACL policy: %v", err)
+	}
+	return path
+}
+
+func authenticateWithKey(t *testing.T, conn *websocket.Conn, apiKey string) {
+	t.Helper()
+
+	SendMessage(t, conn, models.ClientMessage{
+		Type:      "auth",
+		APIKey:    apiKey,
+		RequestID: "auth-1",
+	})
+
+	msg := WaitForAck(t, conn, "auth-1", 2*time.Second)
+	if msg.Status != "authenticated" {
+		t.Fatalf("Expected status authenticated, got %s", msg.Status)
+	}
+}
+
+func deleteTopicWithAuth(t *testing.T, serverURL, topicName, apiKey string) *http.Response {
+	t.Helper()
+
+	req, _ := http.NewRequest("DELETE", serverURL+"/topics/"+topicName, nil)
+	req.Header.Set("X-API-Key", apiKey)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete topic: %v", err)
+	}
+
+	return resp
+}
+
+func listScheduledWithAuth(t *testing.T, serverURL, topic, apiKey string) []models.ScheduledMessageInfo {
+	t.Helper()
+
+	resp := makeGetRequest(t, serverURL+"/scheduled?topic="+topic, apiKey)
+	defer resp.Body.Close()
+
+	var list models.ListScheduledResponse
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode scheduled messages: %v", err)
+	}
+	return list.Scheduled
+}
+
+func cancelScheduledWithAuth(t *testing.T, serverURL, messageID, apiKey string) *http.Response {
+	t.Helper()
+
+	req, _ := http.NewRequest("DELETE", serverURL+"/scheduled/"+messageID, nil)
+	req.Header.Set("X-API-Key", apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to cancel scheduled message: %v", err)
+	}
+	return resp
+}
+
+func expectRESTForbidden(t *testing.T, resp *http.Response) {
+	t.Helper()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status 403, got %d", resp.StatusCode)
+	}
+
+	var errorResp struct {
+		Error models.ErrorInfo `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&errorResp)
+	if errorResp.Error.Code != auth.ErrCodeForbidden {
+		t.Errorf("Expected error code %s, got %s", auth.ErrCodeForbidden, errorResp.Error.Code)
+	}
+}
@@ -0,0 +1,266 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+const testAdminKey = "admin-secret"
+
+// TestAdminKeys_RequiresAdmin tests that only admin keys may use the admin API
+func TestAdminKeys_RequiresAdmin(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"user-key"}, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	resp := adminRequest(t, "GET", server.URL+"/admin/keys", "", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without key, got %d", resp.StatusCode)
+	}
+
+	resp = adminRequest(t, "GET", server.URL+"/admin/keys", "user-key", nil)
+	expectRESTForbidden(t, resp)
+
+	resp = adminRequest(t, "GET", server.URL+"/admin/keys", testAdminKey, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 for admin, got %d", resp.StatusCode)
+	}
+}
+
+// TestAdminKeys_CreateAndList tests creating a key, using it and listing it masked
+func TestAdminKeys_CreateAndList(t *testing.T) {
+	storeFile := filepath.Join(t.TempDir(), "keys.json")
+	server, cleanup := SetupTestServerWithAdmin(t, nil, []string{testAdminKey}, storeFile)
+	defer cleanup()
+
+	created := createManagedKey(t, server.URL, "billing-service", "")
+	if created.Key == "" || created.ID == "" {
+		t.Fatalf("Expected key and id in response, got %+v", created)
+	}
+
+	// The new key works immediately
+	resp := CreateTopicWithAuth(t, server.URL, "billing", created.Key)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 with managed key, got %d", resp.StatusCode)
+	}
+
+	keys := listManagedKeys(t, server.URL)
+	if len(keys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(keys))
+	}
+	if keys[0].Label != "billing-service" || keys[0].Status != auth.KeyStatusActive {
+		t.Errorf("Unexpected key info: %+v", keys[0])
+	}
+	if keys[0].Key == created.Key || !strings.HasSuffix(keys[0].Key, "****") {
+		t.Errorf("Expected masked key in listing, got %s", keys[0].Key)
+	}
+
+	// Only the hash is persisted
+	data, err := os.ReadFile(storeFile)
+	if err != nil {
+		t.Fatalf("Failed to read key store: %v", err)
+	}
+	if strings.Contains(string(data), created.Key) {
+		t.Error("Key store contains the plaintext key")
+	}
+	if !strings.Contains(string(data), auth.HashKey(created.Key)) {
+		t.Error("Key store does not contain the key hash")
+	}
+}
+
+// TestAdminKeys_Persisted tests that managed keys survive a restart
+func TestAdminKeys_Persisted(t *testing.T) {
+	storeFile := filepath.Join(t.TempDir(), "keys.json")
+
+	server, cleanup := SetupTestServerWithAdmin(t, nil, []string{testAdminKey}, storeFile)
+	created := createManagedKey(t, server.URL, "persisted", "")
+	cleanup()
+
+	server, cleanup = SetupTestServerWithAdmin(t, nil, []string{testAdminKey}, storeFile)
+	defer cleanup()
+
+	resp := CreateTopicWithAuth(t, server.URL, "after-restart", created.Key)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected managed key to work after restart, got %d", resp.StatusCode)
+	}
+}
+
+// TestAdminKeys_Revoke tests that revoking a key rejects it and disconnects its clients
+func TestAdminKeys_Revoke(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, nil, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	created := createManagedKey(t, server.URL, "to-revoke", "")
+
+	conn := ConnectWebSocket(t, server.WSURL, "revoked-client")
+	defer conn.Close()
+	authenticateWithKey(t, conn, created.Key)
+
+	resp := adminRequest(t, "DELETE", server.URL+"/admin/keys/"+created.ID, testAdminKey, nil)
+	var revoked models.RevokeAPIKeyResponse
+	json.NewDecoder(resp.Body).Decode(&revoked)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if revoked.Disconnected != 1 {
+		t.Errorf("Expected 1 disconnected client, got %d", revoked.Disconnected)
+	}
+
+	expectClosedWith(t, conn, auth.ErrCodeKeyRevoked)
+
+	resp = CreateTopicWithAuth(t, server.URL, "after-revoke", created.Key)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with revoked key, got %d", resp.StatusCode)
+	}
+
+	if keys := listManagedKeys(t, server.URL); keys[0].Status != auth.KeyStatusRevoked || keys[0].RevokedAt == "" {
+		t.Errorf("Expected revoked key in listing, got %+v", keys[0])
+	}
+
+	resp = adminRequest(t, "DELETE", server.URL+"/admin/keys/"+created.ID, testAdminKey, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409 revoking twice, got %d", resp.StatusCode)
+	}
+
+	resp = adminRequest(t, "DELETE", server.URL+"/admin/keys/does-not-exist", testAdminKey, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown key, got %d", resp.StatusCode)
+	}
+}
+
+// TestAdminKeys_Expiry tests that changing a key's expiry applies to connected clients
+func TestAdminKeys_Expiry(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, nil, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	created := createManagedKey(t, server.URL, "expiring", time.Now().Add(time.Hour).Format(time.RFC3339))
+
+	conn := ConnectWebSocket(t, server.WSURL, "expiring-client")
+	defer conn.Close()
+	authenticateWithKey(t, conn, created.Key)
+
+	// Shorten the expiry to just ahead of now
+	expiresAt := time.Now().Add(2 * time.Second).Format(time.RFC3339)
+	resp := adminRequest(t, "PATCH", server.URL+"/admin/keys/"+created.ID, testAdminKey, models.UpdateAPIKeyRequest{ExpiresAt: expiresAt})
+	var updated models.APIKeyInfo
+	json.NewDecoder(resp.Body).Decode(&updated)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if updated.ExpiresAt != expiresAt {
+		t.Errorf("Expected expires_at %s, got %s", expiresAt, updated.ExpiresAt)
+	}
+
+	expectClosedWith(t, conn, auth.ErrCodeKeyExpired)
+
+	resp = CreateTopicWithAuth(t, server.URL, "after-expiry", created.Key)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with expired key, got %d", resp.StatusCode)
+	}
+
+	resp = adminRequest(t, "POST", server.URL+"/admin/keys", testAdminKey, models.CreateAPIKeyRequest{
+		Label:     "already-expired",
+		ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for past expiry, got %d", resp.StatusCode)
+	}
+}
+
+// Helper functions for admin API tests
+
+func adminRequest(t *testing.T, method, url, apiKey string, body interface{}) *http.Response {
+	t.Helper()
+
+	var reader *bytes.Buffer
+	if body != nil {
+		jsonBody, _ := json.Marshal(body)
+		reader = bytes.NewBuffer(jsonBody)
+	} else {
+		reader = &bytes.Buffer{}
+	}
+
+	req, _ := http.NewRequest(method, url, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make admin request: %v", err)
+	}
+
+	return resp
+}
+
+func createManagedKey(t *testing.T, serverURL, label, expiresAt string) models.CreateAPIKeyResponse {
+	t.Helper()
+
+	resp := adminRequest(t, "POST", serverURL+"/admin/keys", testAdminKey, models.CreateAPIKeyRequest{
+		Label:     label,
+		ExpiresAt: expiresAt,
+	})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating key, got %d", resp.StatusCode)
+	}
+
+	var created models.CreateAPIKeyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode created key: %v", err)
+	}
+	return created
+}
+
+func listManagedKeys(t *testing.T, serverURL string) []models.APIKeyInfo {
+	t.Helper()
+
+	resp := adminRequest(t, "GET", serverURL+"/admin/keys", testAdminKey, nil)
+	defer resp.Body.Close()
+
+	var result models.ListAPIKeysResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode keys: %v", err)
+	}
+	return result.Keys
+}
+
+// expectClosedWith waits for the connection to close, allowing an error with code first
+func expectClosedWith(t *testing.T, conn *websocket.Conn, code string) {
+	t.Helper()
+
+	msg, err := ReceiveMessageNoFail(conn, 5*time.Second)
+	if err == nil {
+		if msg.Type != "error" || msg.Error == nil || msg.Error.Code != code {
+			t.Fatalf("Expected %s error, got %+v", code, msg)
+		}
+		_, err = ReceiveMessageNoFail(conn, 2*time.Second)
+	}
+	if netErr, ok := err.(net.Error); err == nil || (ok && netErr.Timeout()) {
+		t.Errorf("Expected connection to be closed, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/pubsub"
+)
+
+// TestAudit_RESTEvents tests that topic changes and rejected credentials are audited
+func TestAudit_RESTEvents(t *testing.T) {
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	server, cleanup := SetupTestServerWithConfig(t, []string{"audit-key-123"}, func(cfg *config.Config) {
+		cfg.AuditLogFile = auditFile
+	})
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "audit-key-123").Body.Close()
+	CreateTopicWithAuth(t, server.URL, "orders", "audit-key-123").Body.Close()
+	CreateTopicWithAuth(t, server.URL, "orders", "wrong-key-456").Body.Close()
+	CreateTopic(t, server.URL, "no-key").Body.Close()
+	deleteTopicWithAuth(t, server.URL, "orders", "audit-key-123").Body.Close()
+
+	events := readAuditEvents(t, auditFile)
+	expected := []audit.Event{
+		{Action: audit.ActionTopicCreate, Outcome: audit.OutcomeSuccess, Principal: "api_key:audi****", Target: "orders"},
+		{Action: audit.ActionTopicCreate, Outcome: audit.OutcomeFailure, Principal: "api_key:audi****", Target: "orders", Reason: pubsub.ErrTopicExists.Error()},
+		{Action: audit.ActionAuth, Outcome: audit.OutcomeDenied, Target: "POST /topics", Reason: auth.ErrCodeInvalidAPIKey},
+		{Action: audit.ActionAuth, Outcome: audit.OutcomeDenied, Target: "POST /topics", Reason: auth.ErrCodeMissingAPIKey},
+		{Action: audit.ActionTopicDelete, Outcome: audit.OutcomeSuccess, Principal: "api_key:audi****", Target: "orders"},
+	}
+	expectAuditEvents(t, events, expected)
+
+	for _, event := range events {
+		if event.ClientIP != "127.0.0.1" || event.Time.IsZero() {
+			t.Errorf("Expected client IP and time on every event, got %+v", event)
+		}
+	}
+
+	// Full credentials never appear in the audit log
+	data, _ := os.ReadFile(auditFile)
+	if strings.Contains(string(data), "audit-key-123") || strings.Contains(string(data), "wrong-key-456") {
+		t.Error("Expected API keys to be masked in the audit log")
+	}
+}
+
+// TestAudit_AdminEvents tests that key management and forced disconnects are audited
+func TestAudit_AdminEvents(t *testing.T) {
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.AuthEnabled = true
+		cfg.AdminAPIKeys = []string{testAdminKey}
+		cfg.APIKeyStoreFile = filepath.Join(t.TempDir(), "keys.json")
+		cfg.AuditLogFile = auditFile
+	})
+	defer cleanup()
+
+	created := createManagedKey(t, server.URL, "audited", "")
+
+	conn := ConnectWebSocket(t, server.WSURL, "audited-client")
+	defer conn.Close()
+	authenticateWithKey(t, conn, created.Key)
+
+	adminRequest(t, "GET", server.URL+"/admin/keys", created.Key, nil).Body.Close()
+	adminRequest(t, "DELETE", server.URL+"/admin/keys/"+created.ID, testAdminKey, nil).Body.Close()
+	expectClosedWith(t, conn, auth.ErrCodeKeyRevoked)
+
+	clientPrincipal := "api_key:" + auth.MaskKey(created.Key)
+	expected := []audit.Event{
+		{Action: audit.ActionKeyCreate, Outcome: audit.OutcomeSuccess, Principal: "api_key:admi****", Target: created.ID},
+		{Action: audit.ActionAuth, Outcome: audit.OutcomeSuccess, Principal: clientPrincipal, Target: "audited-client"},
+		{Action: audit.ActionAuth, Outcome: audit.OutcomeDenied, Principal: clientPrincipal, Target: "GET /admin/keys", Reason: auth.ErrCodeForbidden},
+		{Action: audit.ActionKeyRevoke, Outcome: audit.OutcomeSuccess, Principal: "api_key:admi****", Target: created.ID},
+		{Action: audit.ActionDisconnect, Outcome: audit.OutcomeSuccess, Principal: clientPrincipal, Target: "audited-client", Reason: pubsub.DisconnectKeyRevoked},
+	}
+	expectAuditEvents(t, waitForAuditEvents(t, auditFile, len(expected)), expected)
+}
+
+// TestAudit_WebSocketAuthFailure tests that rejected WebSocket credentials are audited
+func TestAudit_WebSocketAuthFailure(t *testing.T) {
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	server, cleanup := SetupTestServerWithConfig(t, []string{"valid-key"}, func(cfg *config.Config) {
+		cfg.AuditLogFile = auditFile
+	})
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "bad-auth-client")
+	defer conn.Close()
+	SendMessage(t, conn, models.ClientMessage{Type: "auth", APIKey: "invalid-key", RequestID: "auth-1"})
+	expectClosedWith(t, conn, auth.ErrCodeInvalidAPIKey)
+
+	expected := []audit.Event{
+		{Action: audit.ActionAuth, Outcome: audit.OutcomeDenied, Target: "bad-auth-client", Reason: auth.ErrCodeInvalidAPIKey},
+	}
+	expectAuditEvents(t, waitForAuditEvents(t, auditFile, len(expected)), expected)
+}
+
+// TestAudit_FileRotation tests that the audit file is rotated and old backups are removed
+func TestAudit_FileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewFileSink(path, 512, 2)
+	if err != nil {
+		t.Fatalf("Failed to open audit file: %v", err)
+	}
+	logger := audit.New(sink)
+
+	for i := 0; i < 30; i++ {
+		logger.Record(audit.Event{Action: audit.ActionTopicCreate, Outcome: audit.OutcomeSuccess, Target: fmt.Sprintf("topic-%d", i)})
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close audit log: %v", err)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", filepath.Base(name), err)
+		}
+		if info.Size() > 512 {
+			t.Errorf("Expected %s to be at most 512 bytes, got %d", filepath.Base(name), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only 2 backups to be kept")
+	}
+
+	// The newest event is in the current file
+	events := readAuditEvents(t, path)
+	if len(events) == 0 || events[len(events)-1].Target != "topic-29" {
+		t.Errorf("Expected last event in current file, got %+v", events)
+	}
+}
+
+// Helper functions for audit tests
+
+func readAuditEvents(t *testing.T, path string) []audit.Event {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit file: %v", err)
+	}
+	defer file.Close()
+
+	var events []audit.Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event audit.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Invalid audit line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// waitForAuditEvents waits until the file holds at least count events
+// Disconnects are recorded after the connection closes, so they can trail the client
+func waitForAuditEvents(t *testing.T, path string, count int) []audit.Event {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		events := readAuditEvents(t, path)
+		if len(events) >= count || time.Now().After(deadline) {
+			return events
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// expectAuditEvents compares events ignoring time and client IP
+func expectAuditEvents(t *testing.T, events, expected []audit.Event) {
+	t.Helper()
+
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d audit events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, want := range expected {
+		got := events[i]
+		got.Time, got.ClientIP = time.Time{}, ""
+		if got != want {
+			t.Errorf("Audit event %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/audit"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/pubsub"
+)
+
+// TestClients_ListAndInspect tests listing connected clients and inspecting a single client
+func TestClients_ListAndInspect(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"user-key"}, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "user-key").Body.Close()
+
+	subscriber := ConnectWebSocket(t, server.WSURL, "client-a")
+	defer subscriber.Close()
+	authenticateWithKey(t, subscriber, "user-key")
+	Subscribe(t, subscriber, "orders", 0, "sub-1")
+	WaitForAck(t, subscriber, "sub-1", 2*time.Second)
+
+	idle := ConnectWebSocket(t, server.WSURL, "client-b")
+	defer idle.Close()
+	authenticateWithKey(t, idle, "user-key")
+
+	result := listClients(t, server.URL, "")
+	if result.Total != 2 || len(result.Clients) != 2 {
+		t.Fatalf("Expected 2 clients, got %+v", result)
+	}
+	if result.Clients[0].ID != "client-a" || result.Clients[1].ID != "client-b" {
+		t.Errorf("Expected clients sorted by ID, got %s, %s", result.Clients[0].ID, result.Clients[1].ID)
+	}
+
+	info := getClient(t, server.URL, "client-a")
+	if len(info.Topics) != 1 || info.Topics[0] != "orders" {
+		t.Errorf("Expected subscription to orders, got %v", info.Topics)
+	}
+	if info.Principal == "" || info.Principal == "api_key:user-key" {
+		t.Errorf("Expected masked principal, got %q", info.Principal)
+	}
+	if info.ClientIP == "" || info.RemoteAddr == "" {
+		t.Errorf("Expected client address, got ip %q addr %q", info.ClientIP, info.RemoteAddr)
+	}
+	if info.MessagesSent < 2 {
+		t.Errorf("Expected auth and subscribe acks to be counted, got %d", info.MessagesSent)
+	}
+	if info.QueueSize == 0 || info.ConnectedAt == "" || info.LastActivity < info.ConnectedAt {
+		t.Errorf("Unexpected client info: %+v", info)
+	}
+
+	resp := adminRequest(t, "GET", server.URL+"/admin/clients/missing", testAdminKey, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown client, got %d", resp.StatusCode)
+	}
+}
+
+// TestClients_FilterAndPaginate tests client list filters and pagination
+func TestClients_FilterAndPaginate(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"user-key"}, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "user-key").Body.Close()
+
+	for _, id := range []string{"client-1", "client-2", "client-3"} {
+		conn := ConnectWebSocket(t, server.WSURL, id)
+		defer conn.Close()
+		authenticateWithKey(t, conn, "user-key")
+		if id != "client-2" {
+			Subscribe(t, conn, "orders", 0, "sub-1")
+			WaitForAck(t, conn, "sub-1", 2*time.Second)
+		}
+	}
+
+	result := listClients(t, server.URL, "?topic=orders")
+	if result.Total != 2 || result.Clients[0].ID != "client-1" || result.Clients[1].ID != "client-3" {
+		t.Errorf("Expected subscribers of orders, got %+v", result.Clients)
+	}
+
+	result = listClients(t, server.URL, "?limit=1&offset=1")
+	if result.Total != 3 || len(result.Clients) != 1 || result.Clients[0].ID != "client-2" {
+		t.Errorf("Expected second page of one client, got %+v", result)
+	}
+
+	result = listClients(t, server.URL, "?offset=10")
+	if result.Total != 3 || len(result.Clients) != 0 {
+		t.Errorf("Expected empty page past the end, got %+v", result)
+	}
+
+	result = listClients(t, server.URL, "?offset=9223372036854775807")
+	if result.Total != 3 || len(result.Clients) != 0 {
+		t.Errorf("Expected empty page for the largest offset, got %+v", result)
+	}
+
+	result = listClients(t, server.URL, "?tenant=acme")
+	if result.Total != 0 {
+		t.Errorf("Expected no clients in another tenant, got %+v", result.Clients)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=5000", "?offset=-1", "?limit=abc"} {
+		resp := adminRequest(t, "GET", server.URL+"/admin/clients"+query, testAdminKey, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+// TestClients_RequiresAdmin tests that only admin keys may inspect clients
+func TestClients_RequiresAdmin(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"user-key"}, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	resp := adminRequest(t, "GET", server.URL+"/admin/clients", "user-key", nil)
+	expectRESTForbidden(t, resp)
+}
+
+// TestClients_Disconnect tests that an administrator can close a client with a reason
+func TestClients_Disconnect(t *testing.T) {
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	server, cleanup := SetupTestServerWithConfig(t, []string{"user-key"}, func(cfg *config.Config) {
+		cfg.AdminAPIKeys = []string{testAdminKey}
+		cfg.APIKeyStoreFile = filepath.Join(t.TempDir(), "keys.json")
+		cfg.AuditLogFile = auditFile
+	})
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "noisy-client")
+	defer conn.Close()
+	authenticateWithKey(t, conn, "user-key")
+
+	resp := adminRequest(t, "DELETE", server.URL+"/admin/clients/noisy-client", testAdminKey,
+		models.DisconnectClientRequest{Reason: "Publishing too fast"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// The reason arrives as a final error, followed by a close frame
+	msg := ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "DISCONNECTED" || msg.Error.Message != "Publishing too fast" {
+		t.Fatalf("Expected DISCONNECTED error with reason, got %+v", msg)
+	}
+	_, err := ReceiveMessageNoFail(conn, 2*time.Second)
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok || closeErr.Code != websocket.ClosePolicyViolation || closeErr.Text != "Publishing too fast" {
+		t.Fatalf("Expected policy violation close frame, got %v", err)
+	}
+
+	resp = adminRequest(t, "DELETE", server.URL+"/admin/clients/noisy-client", testAdminKey, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 once disconnected, got %d", resp.StatusCode)
+	}
+
+	clientPrincipal := "api_key:" + auth.MaskKey("user-key")
+	expected := []audit.Event{
+		{Action: audit.ActionAuth, Outcome: audit.OutcomeSuccess, Principal: clientPrincipal, Target: "noisy-client"},
+		{Action: audit.ActionClientClose, Outcome: audit.OutcomeSuccess, Principal: "api_key:admi****", Target: "noisy-client", Reason: "Publishing too fast"},
+		{Action: audit.ActionDisconnect, Outcome: audit.OutcomeSuccess, Principal: clientPrincipal, Target: "noisy-client", Reason: pubsub.DisconnectAdmin},
+		{Action: audit.ActionClientClose, Outcome: audit.OutcomeFailure, Principal: "api_key:admi****", Target: "noisy-client", Reason: pubsub.ErrClientNotFound.Error()},
+	}
+	expectAuditEvents(t, waitForAuditEvents(t, auditFile, len(expected)), expected)
+}
+
+// TestClients_DisconnectInvalidReason tests that reasons too long for a close frame are rejected
+func TestClients_DisconnectInvalidReason(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"user-key"}, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client-1")
+	defer conn.Close()
+	authenticateWithKey(t, conn, "user-key")
+
+	resp := adminRequest(t, "DELETE", server.URL+"/admin/clients/client-1", testAdminKey,
+		models.DisconnectClientRequest{Reason: strings.Repeat("x", 124)})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+	if info := getClient(t, server.URL, "client-1"); info.ID != "client-1" {
+		t.Errorf("Expected client to stay connected, got %+v", info)
+	}
+}
+
+// TestClients_Unsubscribe tests removing a single subscription from a client
+func TestClients_Unsubscribe(t *testing.T) {
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"user-key"}, []string{testAdminKey}, filepath.Join(t.TempDir(), "keys.json"))
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "user-key").Body.Close()
+	CreateTopicWithAuth(t, server.URL, "payments", "user-key").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client-1")
+	defer conn.Close()
+	authenticateWithKey(t, conn, "user-key")
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+	Subscribe(t, conn, "payments", 0, "sub-2")
+	WaitForAck(t, conn, "sub-2", 2*time.Second)
+
+	resp := adminRequest(t, "DELETE", server.URL+"/admin/clients/client-1/subscriptions/orders", testAdminKey, nil)
+	var result models.UnsubscribeClientResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || result.Topic != "orders" {
+		t.Fatalf("Expected status 200 removing subscription, got %d %+v", resp.StatusCode, result)
+	}
+
+	msg := WaitForType(t, conn, "info", 2*time.Second)
+	if msg.Msg != "unsubscribed" || msg.Topic != "orders" {
+		t.Errorf("Expected unsubscribed notice for orders, got %+v", msg)
+	}
+	if info := getClient(t, server.URL, "client-1"); len(info.Topics) != 1 || info.Topics[0] != "payments" {
+		t.Errorf("Expected only payments subscription to remain, got %v", info.Topics)
+	}
+
+	// Events for the removed topic are no longer delivered
+	Publish(t, conn, "orders", uuid.New().String(), "dropped", "pub-1")
+	Publish(t, conn, "payments", uuid.New().String(), "delivered", "pub-2")
+	if event := WaitForEvent(t, conn, 2*time.Second); event.Topic != "payments" {
+		t.Errorf("Expected event for payments, got %+v", event)
+	}
+
+	resp = adminRequest(t, "DELETE", server.URL+"/admin/clients/client-1/subscriptions/orders", testAdminKey, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for removed subscription, got %d", resp.StatusCode)
+	}
+}
+
+// Helper functions for client admin tests
+
+func listClients(t *testing.T, serverURL, query string) models.ListClientsResponse {
+	t.Helper()
+
+	resp := adminRequest(t, "GET", serverURL+"/admin/clients"+query, testAdminKey, nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 listing clients, got %d", resp.StatusCode)
+	}
+
+	var result models.ListClientsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode clients: %v", err)
+	}
+	return result
+}
+
+func getClient(t *testing.T, serverURL, clientID string) models.ClientInfo {
+	t.Helper()
+
+	resp := adminRequest(t, "GET", serverURL+"/admin/clients/"+clientID, testAdminKey, nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 getting client, got %d", resp.StatusCode)
+	}
+
+	var info models.ClientInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode client: %v", err)
+	}
+	return info
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/config"
+)
+
+// TestConfig_File tests that settings are read from the config file with environment variables taking precedence
+func TestConfig_File(t *testing.T) {
+	path := writeConfigFile(t, `
+ring_buffer_size: 50
+api_keys:
+  - key-a
+  - key-b
+tracing_sample_ratio: 0.5
+log_level: debug
+delivery_timestamps: true
+`)
+	t.Setenv("RING_BUFFER_SIZE", "75")
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
+	}
+	if cfg.RingBufferSize != 75 {
+		t.Errorf("Expected environment to override the file, got ring buffer size %d", cfg.RingBufferSize)
+	}
+	if !reflect.DeepEqual(cfg.APIKeys, []string{"key-a", "key-b"}) {
+		t.Errorf("Expected API keys from the file, got %v", cfg.APIKeys)
+	}
+	if cfg.TracingSampleRatio != 0.5 || cfg.LogLevel != "debug" || !cfg.DeliveryTimestamps {
+		t.Errorf("Expected values from the file, got ratio %g, level %q, timestamps %v",
+			cfg.TracingSampleRatio, cfg.LogLevel, cfg.DeliveryTimestamps)
+	}
+	if cfg.SubscriberQueue != 100 || cfg.PingPeriod != 30*time.Second {
+		t.Errorf("Expected defaults for unset values, got queue %d, ping %v", cfg.SubscriberQueue, cfg.PingPeriod)
+	}
+	if cfg.ConfigFile != path {
+		t.Errorf("Expected config file %q, got %q", path, cfg.ConfigFile)
+	}
+}
+
+// TestConfig_Invalid tests that every unparsable, unknown or out of range setting is reported
+func TestConfig_Invalid(t *testing.T) {
+	path := writeConfigFile(t, `
+ring_bufer_size: 50
+ping_period_sec: 90
+`)
+	t.Setenv("SUBSCRIBER_QUEUE_SIZE", "lots")
+
+	_, err := config.Load(path)
+	if err == nil {
+		t.Fatal("Expected invalid configuration to fail")
+	}
+	for _, want := range []string{
+		`SUBSCRIBER_QUEUE_SIZE="lots": expected an integer`,
+		`unknown setting "ring_bufer_size"`,
+		"PING_PERIOD_SEC (1m30s) must be less than PONG_WAIT_SEC (1m0s)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+
+	if _, err := config.Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected missing config file to fail")
+	}
+}
+
+// TestConfig_Reload tests that reloads apply valid changes and keep the configuration when invalid
+func TestConfig_Reload(t *testing.T) {
+	path := writeConfigFile(t, "publish_rate_limit: 10\n")
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
+	}
+
+	var applied []*config.Config
+	reloader := config.NewReloader(cfg, func(next *config.Config) {
+		applied = append(applied, next)
+	})
+
+	rewriteConfigFile(t, path, "publish_rate_limit: 5\n")
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if len(applied) != 1 || applied[0].PublishRateLimit != 5 {
+		t.Fatalf("Expected new rate limit to be applied, got %d reloads", len(applied))
+	}
+
+	rewriteConfigFile(t, path, "publish_rate_limit: -1\n")
+	if err := reloader.Reload(); err == nil || !strings.Contains(err.Error(), "PUBLISH_RATE_LIMIT must not be negative") {
+		t.Errorf("Expected invalid reload to fail, got %v", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("Expected invalid configuration not to be applied, got %d reloads", len(applied))
+	}
+}
+
+// TestConfig_HotReload tests that API keys and rate limits change without dropping connections
+func TestConfig_HotReload(t *testing.T) {
+	path := writeConfigFile(t, `
+api_keys: [key-a]
+config_reload_interval_sec: 1
+`)
+	server, cleanup := SetupTestServerWithConfigFile(t, path)
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "key-a").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client")
+	defer conn.Close()
+	authenticateWithKey(t, conn, "key-a")
+
+	rewriteConfigFile(t, path, `
+api_keys: [key-b]
+publish_rate_limit_per_key: 1
+config_reload_interval_sec: 1
+`)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp := makeGetRequest(t, server.URL+"/topics", "key-b")
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the new API key")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	resp := makeGetRequest(t, server.URL+"/topics", "key-a")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected removed key to be rejected, got %d", resp.StatusCode)
+	}
+
+	// The connection authenticated with the removed key stays open, under the new rate limit
+	Publish(t, conn, "orders", uuid.NewString(), "within limit", "pub-1")
+	if msg := WaitForAck(t, conn, "pub-1", 2*time.Second); msg.Status != "ok" {
+		t.Fatalf("Expected publish on existing connection to succeed, got %s", msg.Status)
+	}
+	Publish(t, conn, "orders", uuid.NewString(), "throttled", "pub-2")
+	expectRateLimited(t, conn, "pub-2")
+}
+
+// Helper functions for config tests
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// rewriteConfigFile replaces the file and moves its modification time forward so the change is seen
+func rewriteConfigFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Failed to update config file time: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestConnectionLimits_Total tests that connections over the server limit are rejected before upgrade
+func TestConnectionLimits_Total(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.MaxConnections = 2
+	})
+	defer cleanup()
+
+	first := ConnectWebSocket(t, server.WSURL, "total-1")
+	second := ConnectWebSocket(t, server.WSURL, "total-2")
+	defer second.Close()
+
+	expectRejectedUpgrade(t, server.WSURL, "total-3", http.StatusServiceUnavailable)
+
+	health := GetHealth(t, server.URL)
+	if health.Connections == nil || health.Connections.Active != 2 || health.Connections.MaxConnections != 2 {
+		t.Errorf("Expected 2 of 2 connections in health, got %+v", health.Connections)
+	}
+
+	// Closing a connection frees its slot
+	first.Close()
+	waitForActiveConnections(t, server.URL, 1)
+	conn := ConnectWebSocket(t, server.WSURL, "total-4")
+	conn.Close()
+}
+
+// TestConnectionLimits_PerIP tests that connections over the per-IP limit are rejected before upgrade
+func TestConnectionLimits_PerIP(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.MaxConnectionsPerIP = 1
+	})
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "ip-1")
+	defer conn.Close()
+
+	expectRejectedUpgrade(t, server.WSURL, "ip-2", http.StatusTooManyRequests)
+
+	if health := GetHealth(t, server.URL); health.Connections.ClientIPs != 1 {
+		t.Errorf("Expected 1 client IP in health, got %d", health.Connections.ClientIPs)
+	}
+}
+
+// TestConnectionLimits_PerKey tests that each API key is limited to its own connection count
+func TestConnectionLimits_PerKey(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, []string{"key-a", "key-b"}, func(cfg *config.Config) {
+		cfg.MaxConnectionsPerKey = 1
+	})
+	defer cleanup()
+
+	first := ConnectWebSocket(t, server.WSURL, "key-a-1")
+	defer first.Close()
+	authenticateWithKey(t, first, "key-a")
+
+	other := ConnectWebSocket(t, server.WSURL, "key-b-1")
+	defer other.Close()
+	authenticateWithKey(t, other, "key-b")
+
+	second := ConnectWebSocket(t, server.WSURL, "key-a-2")
+	defer second.Close()
+	SendMessage(t, second, models.ClientMessage{Type: "auth", APIKey: "key-a", RequestID: "auth-1"})
+	expectClosedWith(t, second, "CONNECTION_LIMIT_EXCEEDED")
+
+	if health := GetHealth(t, server.URL); health.Connections.APIKeys != 2 {
+		t.Errorf("Expected 2 API keys in health, got %d", health.Connections.APIKeys)
+	}
+}
+
+// TestConnectionLimits_Subscriptions tests that clients cannot exceed the subscription limit
+func TestConnectionLimits_Subscriptions(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.MaxSubscriptionsPerClient = 2
+	})
+	defer cleanup()
+
+	for _, topic := range []string{"one", "two", "three"} {
+		CreateTopic(t, server.URL, topic).Body.Close()
+	}
+
+	conn := ConnectWebSocket(t, server.WSURL, "subs-client")
+	defer conn.Close()
+
+	Subscribe(t, conn, "one", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+	Subscribe(t, conn, "two", 0, "sub-2")
+	WaitForAck(t, conn, "sub-2", 2*time.Second)
+
+	Subscribe(t, conn, "three", 0, "sub-3")
+	msg := ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "SUBSCRIPTION_LIMIT_EXCEEDED" {
+		t.Fatalf("Expected SUBSCRIPTION_LIMIT_EXCEEDED error, got %+v", msg)
+	}
+
+	// Re-subscribing to an existing topic is still allowed
+	Subscribe(t, conn, "one", 0, "sub-4")
+	if msg := WaitForAck(t, conn, "sub-4", 2*time.Second); msg.Status != "ok" {
+		t.Errorf("Expected re-subscribe to succeed, got %s", msg.Status)
+	}
+}
+
+// Helper functions for connection limit tests
+
+func expectRejectedUpgrade(t *testing.T, wsURL, clientID string, status int) {
+	t.Helper()
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL+"/ws?client_id="+clientID, nil)
+	if err == nil {
+		conn.Close()
+		t.Fatal("Expected connection to be rejected")
+	}
+	if resp == nil || resp.StatusCode != status {
+		t.Fatalf("Expected status %d, got %v", status, resp)
+	}
+}
+
+func waitForActiveConnections(t *testing.T, serverURL string, active int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if health := GetHealth(t, serverURL); health.Connections != nil && health.Connections.Active == active {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d active connections", active)
+}
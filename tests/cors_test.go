@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/cors"
+)
+
+var testAllowedOrigins = []string{"https://app.example.com", "https://*.example.org"}
+
+// TestOrigins_WebSocket tests that WebSocket upgrades follow the origin allowlist
+func TestOrigins_WebSocket(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.AllowedOrigins = testAllowedOrigins
+	})
+	defer cleanup()
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},
+		{"https://app.example.com", true},
+		{"https://tenant.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"http://app.example.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"https://evil.com", false},
+	}
+
+	for _, tt := range tests {
+		conn, resp, err := dialWithOrigin(server.WSURL, tt.origin)
+		if tt.allowed {
+			if err != nil {
+				t.Errorf("Expected origin %q to be allowed, got %v", tt.origin, err)
+				continue
+			}
+			conn.Close()
+		} else {
+			if err == nil {
+				conn.Close()
+				t.Errorf("Expected origin %q to be rejected", tt.origin)
+				continue
+			}
+			if resp == nil || resp.StatusCode != http.StatusForbidden {
+				t.Errorf("Expected status 403 for origin %q, got %v", tt.origin, resp)
+			}
+		}
+	}
+}
+
+// TestOrigins_DefaultSameOrigin tests that only same-origin browsers are allowed without configuration
+func TestOrigins_DefaultSameOrigin(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	conn, _, err := dialWithOrigin(server.WSURL, server.URL)
+	if err != nil {
+		t.Fatalf("Expected same origin to be allowed, got %v", err)
+	}
+	conn.Close()
+
+	if _, resp, err := dialWithOrigin(server.WSURL, "https://evil.com"); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected cross origin to be rejected with 403, got %v", resp)
+	}
+}
+
+// TestOrigins_REST tests CORS headers, preflight handling and rejection on REST routes
+func TestOrigins_REST(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.AllowedOrigins = testAllowedOrigins
+	})
+	defer cleanup()
+
+	// Preflight from an allowed origin
+	req, _ := http.NewRequest(http.MethodOptions, server.URL+"/topics", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-API-Key")
+	resp := doRequest(t, req)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204 for preflight, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Expected allowed origin header, got %q", got)
+	}
+	if !strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "X-API-Key") {
+		t.Errorf("Expected X-API-Key in allowed headers, got %q", resp.Header.Get("Access-Control-Allow-Headers"))
+	}
+
+	// Simple request from an allowed wildcard origin
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/topics", nil)
+	req.Header.Set("Origin", "https://tenant.example.org")
+	resp = doRequest(t, req)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "https://tenant.example.org" {
+		t.Errorf("Expected 200 with CORS header, got %d %q", resp.StatusCode, resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+
+	// Rejected origin
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/topics", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	resp = doRequest(t, req)
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected 403 without CORS header, got %d %q", resp.StatusCode, resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+
+	// Requests without an Origin header are unaffected
+	if topics := ListTopics(t, server.URL); topics == nil {
+		t.Error("Expected topic listing without Origin header to succeed")
+	}
+}
+
+// TestOrigins_InvalidConfig tests that malformed origin patterns are rejected
+func TestOrigins_InvalidConfig(t *testing.T) {
+	for _, pattern := range []string{"example.com", "https://a.*.example.com", "https://app.example.com/path", "https://*"} {
+		if _, err := cors.NewPolicy([]string{pattern}); err == nil {
+			t.Errorf("Expected pattern %q to be rejected", pattern)
+		}
+	}
+
+	policy, err := cors.NewPolicy([]string{"*"})
+	if err != nil || !policy.Allowed("https://anything.example") {
+		t.Errorf("Expected \"*\" to allow any origin, got %v", err)
+	}
+}
+
+// Helper functions for origin tests
+
+func dialWithOrigin(wsURL, origin string) (*websocket.Conn, *http.Response, error) {
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	return websocket.DefaultDialer.Dial(wsURL+"/ws", header)
+}
+
+func doRequest(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestDuplicatePublishSuppressed tests that a retried publish is acked but not fanned out again
+func TestDuplicatePublishSuppressed(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders")
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	Subscribe(t, sub, "orders", 0, "sub-req")
+	WaitForAck(t, sub, "sub-req", 2*time.Second)
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	messageID := uuid.New().String()
+	Publish(t, pub, "orders", messageID, "first attempt", "pub-req-1")
+	ack := WaitForAck(t, pub, "pub-req-1", 2*time.Second)
+	if ack.Status != "ok" {
+		t.Errorf("Expected status 'ok' for first publish, got '%s'", ack.Status)
+	}
+
+	// Retry with the same message ID
+	Publish(t, pub, "orders", messageID, "retry", "pub-req-2")
+	ack = WaitForAck(t, pub, "pub-req-2", 2*time.Second)
+	if ack.Status != "duplicate" {
+		t.Errorf("Expected status 'duplicate' for retry, got '%s'", ack.Status)
+	}
+
+	event := WaitForEvent(t, sub, 2*time.Second)
+	if event.Message.ID != messageID {
+		t.Errorf("Expected message ID %s, got %s", messageID, event.Message.ID)
+	}
+
+	// The retry must not reach subscribers
+	if msg, err := ReceiveMessageNoFail(sub, 300*time.Millisecond); err == nil {
+		t.Fatalf("Received duplicate delivery: %+v", msg)
+	}
+
+	stats := GetStats(t, server.URL)
+	if stats.Topics["orders"].Messages != 1 {
+		t.Errorf("Expected 1 message in stats, got %d", stats.Topics["orders"].Messages)
+	}
+	if stats.Topics["orders"].DedupHits != 1 {
+		t.Errorf("Expected 1 dedup hit in stats, got %d", stats.Topics["orders"].DedupHits)
+	}
+}
+
+// TestDedupIsPerTopic tests that the same message ID may be published to different topics
+func TestDedupIsPerTopic(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders")
+	CreateTopic(t, server.URL, "invoices")
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	messageID := uuid.New().String()
+	Publish(t, pub, "orders", messageID, "order", "pub-req-1")
+	if ack := WaitForAck(t, pub, "pub-req-1", 2*time.Second); ack.Status != "ok" {
+		t.Errorf("Expected status 'ok', got '%s'", ack.Status)
+	}
+
+	Publish(t, pub, "invoices", messageID, "invoice", "pub-req-2")
+	if ack := WaitForAck(t, pub, "pub-req-2", 2*time.Second); ack.Status != "ok" {
+		t.Errorf("Expected status 'ok' on a different topic, got '%s'", ack.Status)
+	}
+}
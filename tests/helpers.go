@@ -11,12 +11,20 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/admission"
+	"github.com/tarunm/pubsub-system/internal/audit"
 	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/cors"
 	"github.com/tarunm/pubsub-system/internal/handlers"
 	"github.com/tarunm/pubsub-system/internal/models"
 	"github.com/tarunm/pubsub-system/internal/pubsub"
+	"github.com/tarunm/pubsub-system/internal/ratelimit"
+	"github.com/tarunm/pubsub-system/internal/tenant"
+	"github.com/tarunm/pubsub-system/internal/tlsconfig"
+	"github.com/tarunm/pubsub-system/internal/tracing"
 )
 
 // TestServer wraps the HTTP server for testing
@@ -31,97 +39,146 @@ type TestServer struct {
 func SetupTestServer(t *testing.T) (*TestServer, func()) {
 	t.Helper()
 
-	// Find available port
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	// No auth for backward compatibility
+	cfg := newTestConfig()
+	validator := auth.NewAPIKeyValidator([]string{}, false)
+
+	return startTestServer(t, cfg, validator)
+}
+
+// SetupTestServerWithAuth creates and starts a test server with authentication support
+func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (*TestServer, func()) {
+	t.Helper()
+
+	cfg := newTestConfig()
+	cfg.AuthEnabled = authEnabled
+	cfg.APIKeys = apiKeys
+
+	// Initialize authentication
+	validator := auth.NewAPIKeyValidator(apiKeys, authEnabled)
+
+	return startTestServer(t, cfg, validator)
+}
+
+// SetupTestServerWithJWT creates and starts a test server that accepts API keys and
+// JWT bearer tokens signed with hmacSecret or a key from jwksFile
+func SetupTestServerWithJWT(t *testing.T, apiKeys []string, hmacSecret, jwksFile string) (*TestServer, func()) {
+	t.Helper()
+
+	cfg := newTestConfig()
+	cfg.AuthEnabled = true
+	cfg.APIKeys = apiKeys
+	cfg.JWTSecret = hmacSecret
+	cfg.JWTJWKSFile = jwksFile
+
+	validator := auth.NewAPIKeyValidator(apiKeys, true)
+	verifier, err := auth.NewJWTVerifier(hmacSecret, jwksFile, "", "")
 	if err != nil {
-		t.Fatalf("Failed to find available port: %v", err)
+		t.Fatalf("Failed to create JWT verifier: %v", err)
 	}
-	port := listener.Addr().(*net.TCPAddr).Port
-	listener.Close()
+	validator.SetJWTVerifier(verifier)
 
-	// Create test configuration
-	cfg := &config.Config{
-		Port:            fmt.Sprintf("%d", port),
-		GinMode:         "release",
-		RingBufferSize:  100,
-		SubscriberQueue: 100,
-		PingPeriod:      30 * time.Second,
-		PongWait:        60 * time.Second,
-		WriteWait:       10 * time.Second,
-		ReadTimeout:     15 * time.Second,
-		WriteTimeout:    15 * time.Second,
-		IdleTimeout:     0, // No idle timeout for WebSocket tests
-		ShutdownTimeout: 5 * time.Second,
-	}
-
-	// Initialize engine and handlers (no auth for backward compatibility)
-	engine := pubsub.NewPubSubEngine(cfg)
-	validator := auth.NewAPIKeyValidator([]string{}, false)
-	wsHandler := handlers.NewWebSocketHandler(engine, cfg, validator)
-	restHandler := handlers.NewRESTHandler(engine)
+	return startTestServer(t, cfg, validator)
+}
 
-	// Setup router
-	gin.SetMode(gin.ReleaseMode)
-	router := gin.New()
+// SetupTestServerWithACL creates and starts a test server that enforces the ACL
+// policy in aclFile for the given API keys
+func SetupTestServerWithACL(t *testing.T, apiKeys []string, aclFile string) (*TestServer, func()) {
+	t.Helper()
 
-	// Routes
-	router.GET("/ws", wsHandler.HandleWebSocket)
-	router.POST("/topics", restHandler.CreateTopic)
-	router.DELETE("/topics/:name", restHandler.DeleteTopic)
-	router.GET("/topics", restHandler.ListTopics)
-	router.GET("/health", restHandler.GetHealth)
-	router.GET("/stats", restHandler.GetStats)
+	cfg := newTestConfig()
+	cfg.AuthEnabled = true
+	cfg.APIKeys = apiKeys
+	cfg.ACLFile = aclFile
 
-	// Create server
-	srv := &http.Server{
-		Addr:    fmt.Sprintf("127.0.0.1:%d", port),
-		Handler: router,
-	}
+	validator := auth.NewAPIKeyValidator(apiKeys, true)
 
-	// Start server
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			t.Logf("Server error: %v", err)
-		}
-	}()
+	return startTestServer(t, cfg, validator)
+}
 
-	// Wait for server to be ready
-	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
-	wsURL := fmt.Sprintf("ws://127.0.0.1:%d", port)
+// SetupTestServerWithAdmin creates and starts a test server with the admin API enabled
+// Managed keys are persisted to storeFile
+func SetupTestServerWithAdmin(t *testing.T, apiKeys, adminKeys []string, storeFile string) (*TestServer, func()) {
+	t.Helper()
 
-	retries := 10
-	for i := 0; i < retries; i++ {
-		resp, err := http.Get(baseURL + "/health")
-		if err == nil {
-			resp.Body.Close()
-			break
-		}
-		if i == retries-1 {
-			t.Fatalf("Server failed to start: %v", err)
-		}
-		time.Sleep(100 * time.Millisecond)
-	}
+	cfg := newTestConfig()
+	cfg.AuthEnabled = true
+	cfg.APIKeys = apiKeys
+	cfg.AdminAPIKeys = adminKeys
+	cfg.APIKeyStoreFile = storeFile
 
-	testServer := &TestServer{
-		URL:    baseURL,
-		WSURL:  wsURL,
-		server: srv,
-		engine: engine,
-	}
+	validator := auth.NewAPIKeyValidator(apiKeys, true)
 
-	// Cleanup function
-	cleanup := func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		engine.Shutdown()
-		srv.Shutdown(ctx)
+	return startTestServer(t, cfg, validator)
+}
+
+// SetupTestServerWithTenants creates and starts a test server with the tenants in tenantsFile
+// Only tenant API keys are accepted
+func SetupTestServerWithTenants(t *testing.T, tenantsFile string) (*TestServer, func()) {
+	t.Helper()
+
+	cfg := newTestConfig()
+	cfg.AuthEnabled = true
+	cfg.TenantsFile = tenantsFile
+
+	validator := auth.NewAPIKeyValidator([]string{}, true)
+
+	return startTestServer(t, cfg, validator)
+}
+
+// SetupTestServerWithConfig creates and starts a test server after configure adjusts the defaults
+// Authentication is enabled when apiKeys is not empty
+func SetupTestServerWithConfig(t *testing.T, apiKeys []string, configure func(cfg *config.Config)) (*TestServer, func()) {
+	t.Helper()
+
+	cfg := newTestConfig()
+	cfg.AuthEnabled = len(apiKeys) > 0
+	cfg.APIKeys = apiKeys
+	configure(cfg)
+
+	validator := auth.NewAPIKeyValidator(apiKeys, cfg.AuthEnabled)
+
+	return startTestServer(t, cfg, validator)
+}
+
+// SetupTestServerWithConfigFile creates and starts a test server configured from the YAML file at path
+// API keys and rate limits are reloaded when the file changes
+func SetupTestServerWithConfigFile(t *testing.T, path string) (*TestServer, func()) {
+	t.Helper()
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
 	}
+	validator := auth.NewAPIKeyValidator(cfg.APIKeys, cfg.AuthEnabled)
 
-	return testServer, cleanup
+	return startTestServer(t, cfg, validator)
 }
 
-// SetupTestServerWithAuth creates and starts a test server with authentication support
-func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (*TestServer, func()) {
+// newTestConfig returns the configuration shared by all test servers
+func newTestConfig() *config.Config {
+	return &config.Config{
+		GinMode:            "release",
+		RingBufferSize:     100,
+		SubscriberQueue:    100,
+		PingPeriod:         30 * time.Second,
+		PongWait:           60 * time.Second,
+		WriteWait:          10 * time.Second,
+		ReadTimeout:        15 * time.Second,
+		WriteTimeout:       15 * time.Second,
+		IdleTimeout:        0, // No idle timeout for WebSocket tests
+		ShutdownTimeout:    5 * time.Second,
+		DedupWindow:        5 * time.Minute,
+		DedupWindowSize:    1000,
+		RequestTimeout:     5 * time.Second,
+		MaxRequestTimeout:  60 * time.Second,
+		MetricsEnabled:     true,
+		TracingSampleRatio: 1,
+	}
+}
+
+// startTestServer wires handlers and routes for cfg and starts the server on a random port
+func startTestServer(t *testing.T, cfg *config.Config, validator *auth.APIKeyValidator) (*TestServer, func()) {
 	t.Helper()
 
 	// Find available port
@@ -131,41 +188,94 @@ func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (
 	}
 	port := listener.Addr().(*net.TCPAddr).Port
 	listener.Close()
-
-	// Create test configuration
-	cfg := &config.Config{
-		Port:            fmt.Sprintf("%d", port),
-		GinMode:         "release",
-		RingBufferSize:  100,
-		SubscriberQueue: 100,
-		PingPeriod:      30 * time.Second,
-		PongWait:        60 * time.Second,
-		WriteWait:       10 * time.Second,
-		ReadTimeout:     15 * time.Second,
-		WriteTimeout:    15 * time.Second,
-		IdleTimeout:     0,
-		ShutdownTimeout: 5 * time.Second,
-		AuthEnabled:     authEnabled,
-		APIKeys:         apiKeys,
+	cfg.Port = fmt.Sprintf("%d", port)
+
+	// Initialize managed API keys for the admin API
+	var keyStore *auth.KeyStore
+	if len(cfg.AdminAPIKeys) > 0 {
+		var err error
+		keyStore, err = auth.NewKeyStore(cfg.APIKeyStoreFile)
+		if err != nil {
+			t.Fatalf("Failed to load API key store: %v", err)
+		}
+		validator.SetAdminKeys(cfg.AdminAPIKeys)
+		validator.SetKeyStore(keyStore)
 	}
 
-	// Initialize authentication
-	validator := auth.NewAPIKeyValidator(apiKeys, authEnabled)
-
 	// Initialize engine and handlers
 	engine := pubsub.NewPubSubEngine(cfg)
+	traceProvider, err := tracing.Open(cfg.TracingOptions())
+	if err != nil {
+		t.Fatalf("Failed to configure tracing: %v", err)
+	}
+	engine.SetTracerProvider(traceProvider.TracerProvider())
 	wsHandler := handlers.NewWebSocketHandler(engine, cfg, validator)
 	restHandler := handlers.NewRESTHandler(engine)
+	restHandler.SetKeyStore(keyStore)
+
+	// Load topic access control policy
+	if cfg.ACLFile != "" {
+		acl, err := auth.LoadACL(cfg.ACLFile)
+		if err != nil {
+			t.Fatalf("Failed to load ACL policy: %v", err)
+		}
+		wsHandler.SetACL(acl)
+		restHandler.SetACL(acl)
+	}
+
+	// Load tenants and their API keys
+	if cfg.TenantsFile != "" {
+		tenants, err := tenant.LoadRegistry(cfg.TenantsFile)
+		if err != nil {
+			t.Fatalf("Failed to load tenants: %v", err)
+		}
+		for _, tn := range tenants.Tenants() {
+			validator.AddTenantKeys(tn.Name, tn.APIKeys)
+		}
+		wsHandler.SetTenants(tenants)
+		restHandler.SetTenants(tenants)
+	}
+
+	// Configure publish and connection rate limits
+	limiter := ratelimit.New(cfg.RateLimits())
+	wsHandler.SetRateLimiter(limiter)
+
+	// Restrict browser origins for WebSocket upgrades and REST calls
+	originPolicy, err := cors.NewPolicy(cfg.AllowedOrigins)
+	if err != nil {
+		t.Fatalf("Invalid allowed origins: %v", err)
+	}
+	wsHandler.SetOriginPolicy(originPolicy)
+
+	// Configure connection and subscription caps
+	admissionController := admission.NewController(cfg.ConnectionLimits())
+	wsHandler.SetAdmission(admissionController)
+	restHandler.SetAdmission(admissionController)
+
+	// Record security and administrative events
+	auditLog, err := audit.Open(cfg.AuditOptions())
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	validator.SetAuditLog(auditLog)
+	wsHandler.SetAuditLog(auditLog)
+	restHandler.SetAuditLog(auditLog)
+
+	// Count authentication failures alongside the engine's metrics
+	validator.SetMetrics(engine.Metrics())
 
 	// Setup router
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	router.Use(cors.Middleware(originPolicy))
 
 	// Create auth middleware
 	authMiddleware := auth.AuthMiddleware(validator)
 
 	// Unprotected endpoints
 	router.GET("/health", restHandler.GetHealth)
+	router.GET("/livez", restHandler.Livez)
+	router.GET("/readyz", restHandler.Readyz)
 
 	// WebSocket endpoint (has built-in auth)
 	router.GET("/ws", wsHandler.HandleWebSocket)
@@ -178,6 +288,29 @@ func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (
 		protected.DELETE("/topics/:name", restHandler.DeleteTopic)
 		protected.GET("/topics", restHandler.ListTopics)
 		protected.GET("/stats", restHandler.GetStats)
+		protected.GET("/scheduled", restHandler.ListScheduled)
+		protected.DELETE("/scheduled/:id", restHandler.CancelScheduled)
+		if cfg.MetricsEnabled {
+			protected.GET("/metrics", restHandler.GetMetrics)
+		}
+	}
+
+	// Admin API endpoints (admin keys only)
+	if keyStore != nil {
+		adminHandler := handlers.NewAdminHandler(engine, keyStore, cfg.GetWriteWait())
+		adminHandler.SetAuditLog(auditLog)
+		admin := router.Group("/admin")
+		admin.Use(auth.AdminMiddleware(validator))
+		{
+			admin.POST("/keys", adminHandler.CreateKey)
+			admin.GET("/keys", adminHandler.ListKeys)
+			admin.PATCH("/keys/:id", adminHandler.UpdateKey)
+			admin.DELETE("/keys/:id", adminHandler.RevokeKey)
+			admin.GET("/clients", adminHandler.ListClients)
+			admin.GET("/clients/:id", adminHandler.GetClient)
+			admin.DELETE("/clients/:id", adminHandler.DisconnectClient)
+			admin.DELETE("/clients/:id/subscriptions/:topic", adminHandler.UnsubscribeClient)
+		}
 	}
 
 	// Create server
@@ -186,9 +319,38 @@ func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (
 		Handler: router,
 	}
 
+	// Serve HTTPS/WSS when a certificate is configured
+	var certs *tlsconfig.Reloader
+	stopReload := make(chan struct{})
+	if cfg.TLSEnabled() {
+		certs, err = tlsconfig.NewReloader(cfg.TLSOptions())
+		if err != nil {
+			t.Fatalf("Failed to load TLS configuration: %v", err)
+		}
+		srv.TLSConfig = certs.Config()
+		if cfg.TLSReloadInterval > 0 {
+			go certs.Watch(cfg.TLSReloadInterval, stopReload)
+		}
+	}
+
+	// Apply API keys and rate limits from the config file without a restart
+	if cfg.ConfigFile != "" && cfg.ConfigReloadInterval > 0 {
+		settings := config.NewReloader(cfg, func(next *config.Config) {
+			validator.SetAPIKeys(next.APIKeys)
+			limiter.Update(next.RateLimits())
+		})
+		go settings.Watch(cfg.ConfigReloadInterval, stopReload)
+	}
+
 	// Start server
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if certs != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			t.Logf("Server error: %v", err)
 		}
 	}()
@@ -196,12 +358,17 @@ func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (
 	// Wait for server to be ready
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
 	wsURL := fmt.Sprintf("ws://127.0.0.1:%d", port)
+	if certs != nil {
+		baseURL = fmt.Sprintf("https://127.0.0.1:%d", port)
+		wsURL = fmt.Sprintf("wss://127.0.0.1:%d", port)
+	}
 
 	retries := 10
 	for i := 0; i < retries; i++ {
-		resp, err := http.Get(baseURL + "/health")
+		// TLS servers may require client certificates, so only check the port is open
+		conn, err := net.Dial("tcp", srv.Addr)
 		if err == nil {
-			resp.Body.Close()
+			conn.Close()
 			break
 		}
 		if i == retries-1 {
@@ -221,8 +388,11 @@ func SetupTestServerWithAuth(t *testing.T, authEnabled bool, apiKeys []string) (
 	cleanup := func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
+		close(stopReload)
 		engine.Shutdown()
 		srv.Shutdown(ctx)
+		auditLog.Close()
+		traceProvider.Shutdown(ctx)
 	}
 
 	return testServer, cleanup
@@ -453,3 +623,155 @@ func WaitForEvent(t *testing.T, conn *websocket.Conn, timeout time.Duration) mod
 	t.Fatalf("Did not receive event within timeout")
 	return models.ServerMessage{}
 }
+
+// PublishDelayed publishes a message via WebSocket for delivery after delayMs
+func PublishDelayed(t *testing.T, conn *websocket.Conn, topic string, messageID string, payload interface{}, delayMs int64, requestID string) {
+	t.Helper()
+
+	msg := models.ClientMessage{
+		Type:  "publish",
+		Topic: topic,
+		Message: &models.Message{
+			ID:      messageID,
+			Payload: payload,
+		},
+		DelayMs:   delayMs,
+		RequestID: requestID,
+	}
+
+	SendMessage(t, conn, msg)
+}
+
+// ListScheduled lists pending scheduled messages via REST API
+func ListScheduled(t *testing.T, serverURL, topic string) []models.ScheduledMessageInfo {
+	t.Helper()
+
+	resp, err := http.Get(serverURL + "/scheduled?topic=" + topic)
+	if err != nil {
+		t.Fatalf("Failed to list scheduled messages: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result models.ListScheduledResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode scheduled messages: %v", err)
+	}
+
+	return result.Scheduled
+}
+
+// CancelScheduled cancels a scheduled message via REST API
+func CancelScheduled(t *testing.T, serverURL, messageID string) *http.Response {
+	t.Helper()
+
+	req, _ := http.NewRequest("DELETE", serverURL+"/scheduled/"+messageID, nil)
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to cancel scheduled message: %v", err)
+	}
+
+	return resp
+}
+
+// PublishKeyed publishes a message with an ordering key via WebSocket
+func PublishKeyed(t *testing.T, conn *websocket.Conn, topic string, messageID string, key string, payload interface{}, requestID string) {
+	t.Helper()
+
+	msg := models.ClientMessage{
+		Type:  "publish",
+		Topic: topic,
+		Message: &models.Message{
+			ID:      messageID,
+			Key:     key,
+			Payload: payload,
+		},
+		RequestID: requestID,
+	}
+
+	SendMessage(t, conn, msg)
+}
+
+// CreatePartitionedTopic creates a topic with the given number of partitions via REST API
+func CreatePartitionedTopic(t *testing.T, serverURL, topicName string, partitions int) *http.Response {
+	t.Helper()
+
+	body := models.CreateTopicRequest{Name: topicName, Partitions: partitions}
+	jsonBody, _ := json.Marshal(body)
+
+	resp, err := http.Post(
+		serverURL+"/topics",
+		"application/json",
+		bytes.NewBuffer(jsonBody),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+
+	return resp
+}
+
+// SubscribePartitions subscribes to specific partitions of a topic via WebSocket
+func SubscribePartitions(t *testing.T, conn *websocket.Conn, topic string, partitions []int, requestID string) {
+	t.Helper()
+
+	msg := models.ClientMessage{
+		Type:       "subscribe",
+		Topic:      topic,
+		Partitions: partitions,
+		RequestID:  requestID,
+	}
+
+	SendMessage(t, conn, msg)
+}
+
+// SendRequest sends a request message via WebSocket and expects a reply on a private inbox
+func SendRequest(t *testing.T, conn *websocket.Conn, topic string, payload interface{}, timeoutMs int64, requestID string) {
+	t.Helper()
+
+	msg := models.ClientMessage{
+		Type:  "request",
+		Topic: topic,
+		Message: &models.Message{
+			ID:      uuid.New().String(),
+			Payload: payload,
+		},
+		TimeoutMs: timeoutMs,
+		RequestID: requestID,
+	}
+
+	SendMessage(t, conn, msg)
+}
+
+// SendReply answers a request addressed to the given reply inbox via WebSocket
+func SendReply(t *testing.T, conn *websocket.Conn, replyTo string, payload interface{}, requestID string) {
+	t.Helper()
+
+	msg := models.ClientMessage{
+		Type:    "reply",
+		ReplyTo: replyTo,
+		Message: &models.Message{
+			ID:      uuid.New().String(),
+			Payload: payload,
+		},
+		RequestID: requestID,
+	}
+
+	SendMessage(t, conn, msg)
+}
+
+// WaitForType waits for a message of the given type
+func WaitForType(t *testing.T, conn *websocket.Conn, msgType string, timeout time.Duration) models.ServerMessage {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		msg := ReceiveMessage(t, conn, time.Until(deadline))
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+
+	t.Fatalf("Did not receive %s message within timeout", msgType)
+	return models.ServerMessage{}
+}
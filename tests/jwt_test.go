@@ -0,0 +1,314 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+const testJWTSecret = "test-hs256-secret"
+
+// TestJWT_REST_BearerToken tests REST access with HS256 bearer tokens
+func TestJWT_REST_BearerToken(t *testing.T) {
+	server, cleanup := SetupTestServerWithJWT(t, []string{"test-key-123"}, testJWTSecret, "")
+	defer cleanup()
+
+	valid := signHS256(t, testJWTSecret, jwtClaims("svc-orders", time.Hour, nil))
+	resp := createTopicWithBearer(t, server.URL, "orders", valid)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201 with valid token, got %d", resp.StatusCode)
+	}
+
+	// API keys keep working alongside tokens
+	resp = CreateTopicWithAuth(t, server.URL, "payments", "test-key-123")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201 with API key, got %d", resp.StatusCode)
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		expectedCode string
+	}{
+		{
+			name:         "wrong secret",
+			token:        signHS256(t, "other-secret", jwtClaims("svc-orders", time.Hour, nil)),
+			expectedCode: auth.ErrCodeInvalidToken,
+		},
+		{
+			name:         "expired",
+			token:        signHS256(t, testJWTSecret, jwtClaims("svc-orders", -time.Minute, nil)),
+			expectedCode: auth.ErrCodeTokenExpired,
+		},
+		{
+			name:         "missing subject",
+			token:        signHS256(t, testJWTSecret, jwtClaims("", time.Hour, nil)),
+			expectedCode: auth.ErrCodeInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := createTopicWithBearer(t, server.URL, "rejected", tt.token)
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("Expected status 401, got %d", resp.StatusCode)
+			}
+
+			var errorResp struct {
+				Error models.ErrorInfo `json:"error"`
+			}
+			json.NewDecoder(resp.Body).Decode(&errorResp)
+			if errorResp.Error.Code != tt.expectedCode {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, errorResp.Error.Code)
+			}
+		})
+	}
+}
+
+// TestJWT_WebSocket_TokenAuth tests authenticating a WebSocket with a token
+func TestJWT_WebSocket_TokenAuth(t *testing.T) {
+	server, cleanup := SetupTestServerWithJWT(t, nil, testJWTSecret, "")
+	defer cleanup()
+
+	token := signHS256(t, testJWTSecret, jwtClaims("svc-orders", time.Hour, nil))
+	createTopicWithBearer(t, server.URL, "orders", token).Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client-1")
+	defer conn.Close()
+
+	authenticateWithToken(t, conn, token)
+
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	msg := WaitForAck(t, conn, "sub-1", 2*time.Second)
+	if msg.Status != "ok" {
+		t.Errorf("Expected status ok, got %s", msg.Status)
+	}
+}
+
+// TestJWT_WebSocket_ExpiredToken tests that expired tokens are rejected at auth time
+func TestJWT_WebSocket_ExpiredToken(t *testing.T) {
+	server, cleanup := SetupTestServerWithJWT(t, nil, testJWTSecret, "")
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client-1")
+	defer conn.Close()
+
+	SendMessage(t, conn, models.ClientMessage{
+		Type:      "auth",
+		Token:     signHS256(t, testJWTSecret, jwtClaims("svc-orders", -time.Minute, nil)),
+		RequestID: "auth-1",
+	})
+
+	// Should receive error or connection close
+	msg, err := ReceiveMessageNoFail(conn, 2*time.Second)
+	if err != nil {
+		return
+	}
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != auth.ErrCodeTokenExpired {
+		t.Errorf("Expected TOKEN_EXPIRED error, got %+v", msg)
+	}
+}
+
+// TestJWT_WebSocket_ExpiresWhileConnected tests that a connection is closed when its token expires
+func TestJWT_WebSocket_ExpiresWhileConnected(t *testing.T) {
+	server, cleanup := SetupTestServerWithJWT(t, nil, testJWTSecret, "")
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client-1")
+	defer conn.Close()
+
+	authenticateWithToken(t, conn, signHS256(t, testJWTSecret, jwtClaims("svc-orders", 2*time.Second, nil)))
+
+	expectClosedWith(t, conn, auth.ErrCodeTokenExpired)
+}
+
+// TestJWT_TopicClaims tests that topic claims restrict publish and subscribe
+func TestJWT_TopicClaims(t *testing.T) {
+	server, cleanup := SetupTestServerWithJWT(t, []string{"admin-key"}, testJWTSecret, "")
+	defer cleanup()
+
+	for _, topic := range []string{"orders.created", "payments"} {
+		CreateTopicWithAuth(t, server.URL, topic, "admin-key").Body.Close()
+	}
+
+	topics := &auth.TopicClaims{
+		Publish:   []string{"orders.*"},
+		Subscribe: []string{},
+	}
+	conn := ConnectWebSocket(t, server.WSURL, "client-1")
+	defer conn.Close()
+	authenticateWithToken(t, conn, signHS256(t, testJWTSecret, jwtClaims("svc-orders", time.Hour, topics)))
+
+	Publish(t, conn, "orders.created", uuid.NewString(), "allowed", "pub-1")
+	if msg := WaitForAck(t, conn, "pub-1", 2*time.Second); msg.Status != "ok" {
+		t.Errorf("Expected publish to orders.created to succeed, got %s", msg.Status)
+	}
+
+	Publish(t, conn, "payments", uuid.NewString(), "denied", "pub-2")
+	expectForbidden(t, conn, "pub-2")
+
+	Subscribe(t, conn, "orders.created", 0, "sub-1")
+	expectForbidden(t, conn, "sub-1")
+}
+
+// TestJWT_JWKS tests RS256 and ES256 tokens verified with keys from a JWKS file
+func TestJWT_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-1",
+				"n":   b64(rsaKey.N),
+				"e":   b64(big.NewInt(int64(rsaKey.E))),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-1",
+				"crv": "P-256",
+				"x":   b64(ecKey.X),
+				"y":   b64(ecKey.Y),
+			},
+		},
+	}
+	data, _ := json.Marshal(jwks)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, data, 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS file: %v", err)
+	}
+
+	server, cleanup := SetupTestServerWithJWT(t, nil, "", jwksFile)
+	defer cleanup()
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    interface{}
+		status int
+	}{
+		{name: "RS256", method: jwt.SigningMethodRS256, kid: "rsa-1", key: rsaKey, status: http.StatusCreated},
+		{name: "ES256", method: jwt.SigningMethodES256, kid: "ec-1", key: ecKey, status: http.StatusCreated},
+		{name: "unknown kid", method: jwt.SigningMethodRS256, kid: "rsa-2", key: rsaKey, status: http.StatusUnauthorized},
+		{name: "HS256 not configured", method: jwt.SigningMethodHS256, kid: "", key: []byte(testJWTSecret), status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(tt.method, jwtClaims("svc-"+tt.name, time.Hour, nil))
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+			signed, err := token.SignedString(tt.key)
+			if err != nil {
+				t.Fatalf("Failed to sign token: %v", err)
+			}
+
+			resp := createTopicWithBearer(t, server.URL, "topic-"+tt.kid+tt.name, signed)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// Helper functions for token authentication
+
+func jwtClaims(subject string, ttl time.Duration, topics *auth.TopicClaims) auth.TokenClaims {
+	return auth.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+		Topics: topics,
+	}
+}
+
+func signHS256(t *testing.T, secret string, claims auth.TokenClaims) string {
+	t.Helper()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func b64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func createTopicWithBearer(t *testing.T, serverURL, topicName, token string) *http.Response {
+	t.Helper()
+
+	body := map[string]string{"name": topicName}
+	jsonBody, _ := json.Marshal(body)
+
+	req, _ := http.NewRequest("POST", serverURL+"/topics", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+
+	return resp
+}
+
+func authenticateWithToken(t *testing.T, conn *websocket.Conn, token string) {
+	t.Helper()
+
+	SendMessage(t, conn, models.ClientMessage{
+		Type:      "auth",
+		Token:     token,
+		RequestID: "auth-1",
+	})
+
+	msg := WaitForAck(t, conn, "auth-1", 2*time.Second)
+	if msg.Status != "authenticated" {
+		t.Fatalf("Expected status authenticated, got %s", msg.Status)
+	}
+}
+
+func expectForbidden(t *testing.T, conn *websocket.Conn, requestID string) {
+	t.Helper()
+
+	msg := ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.RequestID != requestID {
+		t.Fatalf("Expected error for %s, got %+v", requestID, msg)
+	}
+	if msg.Error.Code != auth.ErrCodeForbidden {
+		t.Errorf("Expected error code %s, got %s", auth.ErrCodeForbidden, msg.Error.Code)
+	}
+}
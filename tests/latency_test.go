@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestLatency_Stats tests that publish-to-write latency is reported per topic, excluding replays
+func TestLatency_Stats(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+	CreateTopic(t, server.URL, "idle").Body.Close()
+
+	first := ConnectWebSocket(t, server.WSURL, "first")
+	defer first.Close()
+	Subscribe(t, first, "orders", 0, "sub-1")
+	WaitForAck(t, first, "sub-1", 2*time.Second)
+
+	for i := 0; i < 5; i++ {
+		Publish(t, first, "orders", uuid.New().String(), i, "pub")
+		WaitForEvent(t, first, 2*time.Second)
+	}
+	latency := waitForLatencyCount(t, server.URL, "orders", 5)
+
+	if latency.P50 <= 0 || latency.P50 > latency.P95 || latency.P95 > latency.P99 {
+		t.Errorf("Expected ordered positive percentiles, got %+v", latency)
+	}
+	if latency.P99 > 1000 {
+		t.Errorf("Expected local delivery well under a second, got %+v", latency)
+	}
+	if idle := GetStats(t, server.URL).Topics["idle"].Latency; idle != (models.LatencyStats{}) {
+		t.Errorf("Expected no latency on idle topic, got %+v", idle)
+	}
+
+	// Replayed history measures the subscriber joining late, not delivery
+	second := ConnectWebSocket(t, server.WSURL, "second")
+	defer second.Close()
+	Subscribe(t, second, "orders", 5, "sub-2")
+	WaitForAck(t, second, "sub-2", 2*time.Second)
+	for i := 0; i < 5; i++ {
+		WaitForEvent(t, second, 2*time.Second)
+	}
+
+	Publish(t, first, "orders", uuid.New().String(), "live", "pub-live")
+	WaitForEvent(t, second, 2*time.Second)
+	waitForLatencyCount(t, server.URL, "orders", 7)
+
+	expectMetrics(t, server.URL, "", `pubsub_delivery_latency_seconds_count{topic="orders"} 7`)
+}
+
+// TestLatency_DeliveryTimestamps tests publish and delivery timestamps on events when enabled
+func TestLatency_DeliveryTimestamps(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.DeliveryTimestamps = true
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client")
+	defer conn.Close()
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+
+	before := time.Now()
+	Publish(t, conn, "orders", uuid.New().String(), "hello", "pub")
+	event := WaitForEvent(t, conn, 2*time.Second)
+
+	published, err := time.Parse(time.RFC3339Nano, event.PublishedAt)
+	if err != nil {
+		t.Fatalf("Expected RFC3339 published_at, got %q: %v", event.PublishedAt, err)
+	}
+	delivered, err := time.Parse(time.RFC3339Nano, event.DeliveredAt)
+	if err != nil {
+		t.Fatalf("Expected RFC3339 delivered_at, got %q: %v", event.DeliveredAt, err)
+	}
+	if published.Before(before) || delivered.Before(published) || delivered.After(time.Now()) {
+		t.Errorf("Expected %v <= published_at %v <= delivered_at %v <= now", before, published, delivered)
+	}
+}
+
+// TestLatency_DeliveryTimestampsDisabled tests that events carry no delivery timestamps by default
+func TestLatency_DeliveryTimestampsDisabled(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client")
+	defer conn.Close()
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+
+	Publish(t, conn, "orders", uuid.New().String(), "hello", "pub")
+	event := WaitForEvent(t, conn, 2*time.Second)
+	if event.PublishedAt != "" || event.DeliveredAt != "" {
+		t.Errorf("Expected no delivery timestamps, got published_at=%q delivered_at=%q", event.PublishedAt, event.DeliveredAt)
+	}
+}
+
+// Helper functions for latency tests
+
+// waitForLatencyCount waits until a topic has timed count events
+// Latency is recorded after the write returns, so it can trail the client
+func waitForLatencyCount(t *testing.T, serverURL, topic string, count uint64) models.LatencyStats {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	var latency models.LatencyStats
+	for time.Now().Before(deadline) {
+		latency = GetStats(t, serverURL).Topics[topic].Latency
+		if latency.Count == count {
+			return latency
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d timed events on %s, got %+v", count, topic, latency)
+	return latency
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tarunm/pubsub-system/internal/logging"
+)
+
+// TestLogging_JSONFormat tests that records are written as JSON with their attributes
+func TestLogging_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: "info", Format: logging.FormatJSON})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.With("client_id", "client-1").Info("Client subscribed", "topic", "orders")
+
+	records := readLogRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	record := records[0]
+	if record["level"] != "INFO" || record["msg"] != "Client subscribed" ||
+		record["client_id"] != "client-1" || record["topic"] != "orders" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
+
+// TestLogging_Level tests that records below the configured level are discarded
+func TestLogging_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: "warn", Format: logging.FormatText})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Debug("Received message")
+	logger.Info("Client registered")
+	logger.Warn("Slow consumer detected")
+
+	output := buf.String()
+	if strings.Contains(output, "Received message") || strings.Contains(output, "Client registered") {
+		t.Errorf("Expected debug and info records to be discarded, got %q", output)
+	}
+	if !strings.Contains(output, `level=WARN msg="Slow consumer detected"`) {
+		t.Errorf("Expected warn record in text format, got %q", output)
+	}
+}
+
+// TestLogging_Sampling tests that repeated records are sampled per message
+func TestLogging_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Format: logging.FormatJSON, SampleInitial: 3, SampleThereafter: 5})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		logger.Info("Message published", "n", i)
+	}
+	logger.With("client_id", "client-1").Info("Client registered")
+
+	counts := make(map[string]int)
+	var published []float64
+	for _, record := range readLogRecords(t, &buf) {
+		counts[record["msg"].(string)]++
+		if record["msg"] == "Message published" {
+			published = append(published, record["n"].(float64))
+		}
+	}
+
+	// The first 3, then every 5th after them: records 8, 13 and 18 (1-based)
+	expected := []float64{0, 1, 2, 7, 12, 17}
+	if len(published) != len(expected) {
+		t.Fatalf("Expected %d sampled records, got %v", len(expected), published)
+	}
+	for i := range expected {
+		if published[i] != expected[i] {
+			t.Errorf("Expected sampled records %v, got %v", expected, published)
+			break
+		}
+	}
+	if counts["Client registered"] != 1 {
+		t.Error("Expected other messages to be sampled separately")
+	}
+}
+
+// TestLogging_InvalidOptions tests that unknown levels and formats are rejected
+func TestLogging_InvalidOptions(t *testing.T) {
+	if _, err := logging.New(&bytes.Buffer{}, logging.Options{Level: "verbose"}); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if _, err := logging.New(&bytes.Buffer{}, logging.Options{Format: "xml"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if err := logging.SetLevel("verbose"); err == nil {
+		t.Error("Expected an error when changing to an unknown level")
+	}
+}
+
+// Helper functions for logging tests
+
+func readLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/metrics"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestMetrics_Endpoint tests that /metrics serves every metric family in the text format
+func TestMetrics_Endpoint(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != metrics.ContentType {
+		t.Errorf("Expected content type %q, got %q", metrics.ContentType, got)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	for _, line := range []string{
+		"# TYPE pubsub_messages_published_total counter",
+		"# TYPE pubsub_messages_delivered_total counter",
+		"# TYPE pubsub_messages_dropped_total counter",
+		"# TYPE pubsub_client_disconnects_total counter",
+		"# TYPE pubsub_auth_failures_total counter",
+		"# TYPE pubsub_subscriber_queue_depth histogram",
+		"# TYPE pubsub_fanout_duration_seconds histogram",
+		"# TYPE pubsub_delivery_latency_seconds histogram",
+		"# TYPE pubsub_websocket_connections gauge",
+		"# TYPE pubsub_websocket_connections_opened_total counter",
+		"# TYPE pubsub_websocket_connections_rejected_total counter",
+		"pubsub_topics 0",
+		"pubsub_clients 0",
+		`pubsub_fanout_duration_seconds_bucket{le="+Inf"} 0`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("Expected metrics to contain %q", line)
+		}
+	}
+}
+
+// TestMetrics_PublishAndDelivery tests publish, delivery, fan-out and connection metrics
+func TestMetrics_PublishAndDelivery(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	sub1 := ConnectWebSocket(t, server.WSURL, "metrics-sub-1")
+	defer sub1.Close()
+	sub2 := ConnectWebSocket(t, server.WSURL, "metrics-sub-2")
+	defer sub2.Close()
+	Subscribe(t, sub1, "orders", 0, "sub-1")
+	WaitForAck(t, sub1, "sub-1", 2*time.Second)
+	Subscribe(t, sub2, "orders", 0, "sub-2")
+	WaitForAck(t, sub2, "sub-2", 2*time.Second)
+
+	publisher := ConnectWebSocket(t, server.WSURL, "metrics-publisher")
+	defer publisher.Close()
+	for i := 0; i < 3; i++ {
+		requestID := uuid.New().String()
+		Publish(t, publisher, "orders", uuid.New().String(), map[string]int{"n": i}, requestID)
+		WaitForAck(t, publisher, requestID, 2*time.Second)
+	}
+
+	expectMetrics(t, server.URL, "",
+		`pubsub_messages_published_total{topic="orders"} 3`,
+		`pubsub_messages_delivered_total{topic="orders"} 6`,
+		"pubsub_fanout_duration_seconds_count 3",
+		"pubsub_websocket_connections 3",
+		"pubsub_websocket_connections_opened_total 3",
+		"pubsub_topics 1",
+		"pubsub_clients 3",
+	)
+
+	// A deleted topic's series are removed
+	DeleteTopic(t, server.URL, "orders").Body.Close()
+	body := scrapeMetrics(t, server.URL, "")
+	if strings.Contains(body, `topic="orders"`) {
+		t.Error("Expected deleted topic series to be removed")
+	}
+}
+
+// TestMetrics_AuthFailures tests that rejected credentials are counted by transport and code
+func TestMetrics_AuthFailures(t *testing.T) {
+	server, cleanup := SetupTestServerWithAuth(t, true, []string{"metrics-key-123"})
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "wrong-key").Body.Close()
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "bad-auth-client")
+	defer conn.Close()
+	SendMessage(t, conn, models.ClientMessage{Type: "auth", APIKey: "wrong-key", RequestID: "auth-1"})
+	expectClosedWith(t, conn, auth.ErrCodeInvalidAPIKey)
+
+	expectMetrics(t, server.URL, "metrics-key-123",
+		`pubsub_auth_failures_total{transport="rest",code="INVALID_API_KEY"} 1`,
+		`pubsub_auth_failures_total{transport="rest",code="MISSING_API_KEY"} 1`,
+		`pubsub_auth_failures_total{transport="websocket",code="INVALID_API_KEY"} 1`,
+	)
+}
+
+// TestMetrics_RequiresAuth tests that /metrics needs a credential outside any tenant when auth is enabled
+func TestMetrics_RequiresAuth(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, []string{"operator-key"}, func(cfg *config.Config) {
+		cfg.TenantsFile = writeTenants(t, testTenants)
+	})
+	defer cleanup()
+
+	for _, tc := range []struct {
+		apiKey string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"alpha-key", http.StatusForbidden},
+		{"operator-key", http.StatusOK},
+	} {
+		resp := makeGetRequest(t, server.URL+"/metrics", tc.apiKey)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("Expected status %d for key %q, got %d", tc.status, tc.apiKey, resp.StatusCode)
+		}
+	}
+}
+
+// TestMetrics_Disabled tests that /metrics is not served when metrics are disabled
+func TestMetrics_Disabled(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.MetricsEnabled = false
+	})
+	defer cleanup()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+// Helper functions for metrics tests
+
+func scrapeMetrics(t *testing.T, serverURL, apiKey string) string {
+	t.Helper()
+
+	resp := makeGetRequest(t, serverURL+"/metrics", apiKey)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+	return string(body)
+}
+
+// expectMetrics waits until every sample line is present
+// Deliveries and disconnects are counted asynchronously, so samples can trail the client
+func expectMetrics(t *testing.T, serverURL, apiKey string, samples ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		body := scrapeMetrics(t, serverURL, apiKey)
+		var missing []string
+		for _, sample := range samples {
+			if !strings.Contains(body, sample+"\n") {
+				missing = append(missing, sample)
+			}
+		}
+		if len(missing) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected metrics %q in:\n%s", missing, body)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
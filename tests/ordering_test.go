@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// orderedEvent is a keyed event as observed by one subscriber
+type orderedEvent struct {
+	ID        string
+	Publisher int
+	Seq       int
+}
+
+// TestPerKeyOrderingConcurrentPublishers verifies per-key FIFO delivery while
+// several publishers interleave messages on the same set of keys
+func TestPerKeyOrderingConcurrentPublishers(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "accounts")
+
+	numPublishers := 8
+	numSubscribers := 4
+	messagesPerPublisher := 40
+	keys := []string{"account-1", "account-2", "account-3", "account-4"}
+	expected := numPublishers * messagesPerPublisher
+
+	// Subscribers record the per-key sequence they observe
+	observed := make([]map[string][]orderedEvent, numSubscribers)
+	var subsReady sync.WaitGroup
+	var subsDone sync.WaitGroup
+
+	for i := 0; i < numSubscribers; i++ {
+		subsReady.Add(1)
+		subsDone.Add(1)
+		observed[i] = make(map[string][]orderedEvent)
+
+		go func(id int) {
+			defer subsDone.Done()
+
+			conn := ConnectWebSocket(t, server.WSURL, fmt.Sprintf("ordered-sub-%d", id))
+			defer conn.Close()
+
+			requestID := fmt.Sprintf("sub-req-%d", id)
+			Subscribe(t, conn, "accounts", 0, requestID)
+			WaitForAck(t, conn, requestID, 2*time.Second)
+			subsReady.Done()
+
+			received := 0
+			nextOffset := int64(0)
+			for received < expected {
+				msg, err := ReceiveMessageNoFail(conn, 5*time.Second)
+				if err != nil {
+					t.Errorf("Subscriber %d stopped after %d/%d events: %v", id, received, expected, err)
+					return
+				}
+				if msg.Type != "event" || msg.Message == nil {
+					continue
+				}
+
+				// Concurrent fan-outs must still be enqueued in offset order
+				if msg.Message.Offset != nextOffset {
+					t.Errorf("Subscriber %d: expected offset %d, got %d", id, nextOffset, msg.Message.Offset)
+				}
+				nextOffset = msg.Message.Offset + 1
+
+				payload := msg.Message.Payload.(map[string]interface{})
+				observed[id][msg.Message.Key] = append(observed[id][msg.Message.Key], orderedEvent{
+					ID:        msg.Message.ID,
+					Publisher: int(payload["publisher"].(float64)),
+					Seq:       int(payload["seq"].(float64)),
+				})
+				received++
+			}
+		}(i)
+	}
+
+	subsReady.Wait()
+
+	// Publishers interleave on shared keys
+	var pubsDone sync.WaitGroup
+	for i := 0; i < numPublishers; i++ {
+		pubsDone.Add(1)
+		go func(id int) {
+			defer pubsDone.Done()
+
+			conn := ConnectWebSocket(t, server.WSURL, fmt.Sprintf("ordered-pub-%d", id))
+			defer conn.Close()
+
+			for seq := 0; seq < messagesPerPublisher; seq++ {
+				requestID := uuid.New().String()
+				key := keys[seq%len(keys)]
+				payload := map[string]interface{}{"publisher": id, "seq": seq}
+
+				SendMessage(t, conn, models.ClientMessage{
+					Type:  "publish",
+					Topic: "accounts",
+					Message: &models.Message{
+						ID:      uuid.New().String(),
+						Key:     key,
+						Payload: payload,
+					},
+					RequestID: requestID,
+				})
+				WaitForAck(t, conn, requestID, 5*time.Second)
+			}
+		}(i)
+	}
+
+	pubsDone.Wait()
+	subsDone.Wait()
+
+	for sub := 0; sub < numSubscribers; sub++ {
+		for _, key := range keys {
+			// Each publisher's messages for a key must arrive in publish order
+			lastSeq := make(map[int]int)
+			for _, ev := range observed[sub][key] {
+				if prev, ok := lastSeq[ev.Publisher]; ok && ev.Seq <= prev {
+					t.Errorf("Subscriber %d key %s: publisher %d seq %d arrived after seq %d",
+						sub, key, ev.Publisher, ev.Seq, prev)
+				}
+				lastSeq[ev.Publisher] = ev.Seq
+			}
+
+			// Every subscriber must observe the same per-key order
+			if sub == 0 {
+				continue
+			}
+			reference := observed[0][key]
+			got := observed[sub][key]
+			if len(got) != len(reference) {
+				t.Errorf("Subscriber %d key %s: got %d events, subscriber 0 got %d",
+					sub, key, len(got), len(reference))
+				continue
+			}
+			for i := range got {
+				if got[i].ID != reference[i].ID {
+					t.Errorf("Subscriber %d key %s: order diverges from subscriber 0 at position %d", sub, key, i)
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestOrderingKeyInEvent tests that the ordering key is carried through to subscribers
+func TestOrderingKeyInEvent(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "accounts")
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	Subscribe(t, sub, "accounts", 0, "sub-req")
+	WaitForAck(t, sub, "sub-req", 2*time.Second)
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	PublishKeyed(t, pub, "accounts", uuid.New().String(), "account-42", "opened", "pub-req")
+	WaitForAck(t, pub, "pub-req", 2*time.Second)
+
+	event := WaitForEvent(t, sub, 2*time.Second)
+	if event.Message.Key != "account-42" {
+		t.Errorf("Expected key 'account-42', got '%s'", event.Message.Key)
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestCreatePartitionedTopic tests creating topics with a partition count
+func TestCreatePartitionedTopic(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	resp := CreatePartitionedTopic(t, server.URL, "clicks", 4)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	topics := ListTopics(t, server.URL)
+	if len(topics) != 1 || topics[0].Partitions != 4 {
+		t.Errorf("Expected one topic with 4 partitions, got %+v", topics)
+	}
+
+	resp = CreatePartitionedTopic(t, server.URL, "invalid", -1)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for negative partitions, got %d", resp.StatusCode)
+	}
+
+	resp = CreatePartitionedTopic(t, server.URL, "too-many", 100000)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too many partitions, got %d", resp.StatusCode)
+	}
+}
+
+// TestKeyedPublishSticksToPartition tests that a key always routes to the same partition
+// and that offsets are assigned per partition
+func TestKeyedPublishSticksToPartition(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreatePartitionedTopic(t, server.URL, "clicks", 4).Body.Close()
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	Subscribe(t, sub, "clicks", 0, "sub-req")
+	WaitForAck(t, sub, "sub-req", 2*time.Second)
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	for i := 0; i < 5; i++ {
+		requestID := fmt.Sprintf("pub-req-%d", i)
+		PublishKeyed(t, pub, "clicks", uuid.New().String(), "user-7", i, requestID)
+		WaitForAck(t, pub, requestID, 2*time.Second)
+	}
+
+	partition := -1
+	for i := 0; i < 5; i++ {
+		event := WaitForEvent(t, sub, 2*time.Second)
+		if partition == -1 {
+			partition = event.Message.Partition
+		}
+		if event.Message.Partition != partition {
+			t.Errorf("Key moved from partition %d to %d", partition, event.Message.Partition)
+		}
+		if event.Message.Offset != int64(i) {
+			t.Errorf("Expected offset %d, got %d", i, event.Message.Offset)
+		}
+	}
+}
+
+// TestRoundRobinAndPartitionSubscription tests round-robin routing of unkeyed messages
+// and subscribing to a single partition
+func TestRoundRobinAndPartitionSubscription(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreatePartitionedTopic(t, server.URL, "clicks", 4).Body.Close()
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	SubscribePartitions(t, sub, "clicks", []int{2}, "sub-req")
+	WaitForAck(t, sub, "sub-req", 2*time.Second)
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	for i := 0; i < 8; i++ {
+		requestID := fmt.Sprintf("pub-req-%d", i)
+		Publish(t, pub, "clicks", uuid.New().String(), i, requestID)
+		WaitForAck(t, pub, requestID, 2*time.Second)
+	}
+
+	// Only partition 2 messages are delivered
+	for i := 0; i < 2; i++ {
+		event := WaitForEvent(t, sub, 2*time.Second)
+		if event.Message.Partition != 2 {
+			t.Errorf("Expected partition 2, got %d", event.Message.Partition)
+		}
+	}
+	if msg, err := ReceiveMessageNoFail(sub, 300*time.Millisecond); err == nil {
+		t.Fatalf("Received message from unsubscribed partition: %+v", msg)
+	}
+
+	stats := GetStats(t, server.URL)
+	for _, p := range stats.Topics["clicks"].Partitions {
+		if p.Offset != 2 {
+			t.Errorf("Expected partition %d to hold 2 messages, got %d", p.ID, p.Offset)
+		}
+	}
+}
+
+// TestSubscribeInvalidPartition tests subscribing to a partition that does not exist
+func TestSubscribeInvalidPartition(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreatePartitionedTopic(t, server.URL, "clicks", 2).Body.Close()
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	SubscribePartitions(t, sub, "clicks", []int{5}, "sub-req")
+
+	msg := ReceiveMessage(t, sub, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "BAD_REQUEST" {
+		t.Fatalf("Expected BAD_REQUEST error, got %+v", msg)
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestProbes_Ready tests that a healthy server passes liveness and readiness
+func TestProbes_Ready(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	live := expectProbe(t, server.URL, "/livez", http.StatusOK)
+	if live.Status != "ok" || len(live.Checks) != 1 || live.Checks[0].Name != "engine_lock" {
+		t.Errorf("Expected engine_lock check to pass, got %+v", live)
+	}
+
+	ready := expectProbe(t, server.URL, "/readyz", http.StatusOK)
+	for _, name := range []string{"engine", "connections"} {
+		if check := findCheck(ready.Checks, name); check.Status != "ok" {
+			t.Errorf("Expected %s check to pass, got %+v", name, check)
+		}
+	}
+	if check := findCheck(ready.Checks, "key_store"); check.Name != "" {
+		t.Errorf("Expected no key_store check without the admin API, got %+v", check)
+	}
+
+	health := GetHealth(t, server.URL)
+	if health.Status != "ok" || len(health.Checks) != len(live.Checks)+len(ready.Checks) {
+		t.Errorf("Expected health to report every check as ok, got %+v", health)
+	}
+}
+
+// TestProbes_ConnectionLimit tests that readiness fails while the server is full
+func TestProbes_ConnectionLimit(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.MaxConnections = 1
+	})
+	defer cleanup()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client")
+
+	ready := expectProbe(t, server.URL, "/readyz", http.StatusServiceUnavailable)
+	check := findCheck(ready.Checks, "connections")
+	if ready.Status != "unavailable" || check.Status != "fail" || check.Reason != "server connection limit reached (1/1)" {
+		t.Errorf("Expected connections check to fail, got %+v", ready)
+	}
+	expectProbe(t, server.URL, "/livez", http.StatusOK)
+
+	// /health reports the failure without failing itself
+	if health := GetHealth(t, server.URL); health.Status != "unavailable" {
+		t.Errorf("Expected unavailable health status, got %q", health.Status)
+	}
+
+	conn.Close()
+	waitForActiveConnections(t, server.URL, 0)
+	expectProbe(t, server.URL, "/readyz", http.StatusOK)
+}
+
+// TestProbes_ShuttingDown tests that readiness fails once the engine shuts down while liveness passes
+func TestProbes_ShuttingDown(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	server.engine.Shutdown()
+
+	ready := expectProbe(t, server.URL, "/readyz", http.StatusServiceUnavailable)
+	if check := findCheck(ready.Checks, "engine"); check.Status != "fail" || check.Reason != "shutting down" {
+		t.Errorf("Expected engine check to fail, got %+v", check)
+	}
+	expectProbe(t, server.URL, "/livez", http.StatusOK)
+}
+
+// TestProbes_KeyStoreNotWritable tests that readiness fails when managed keys cannot be persisted
+func TestProbes_KeyStoreNotWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create store directory: %v", err)
+	}
+	server, cleanup := SetupTestServerWithAdmin(t, []string{"client-key"}, []string{testAdminKey}, filepath.Join(dir, "keys.json"))
+	defer cleanup()
+
+	ready := expectProbe(t, server.URL, "/readyz", http.StatusOK)
+	if check := findCheck(ready.Checks, "key_store"); check.Status != "ok" {
+		t.Errorf("Expected key_store check to pass, got %+v", check)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Failed to remove store directory: %v", err)
+	}
+
+	ready = expectProbe(t, server.URL, "/readyz", http.StatusServiceUnavailable)
+	if check := findCheck(ready.Checks, "key_store"); check.Status != "fail" || check.Reason == "" {
+		t.Errorf("Expected key_store check to fail with a reason, got %+v", check)
+	}
+}
+
+// Helper functions for probe tests
+
+func expectProbe(t *testing.T, serverURL, path string, expectedStatus int) models.ProbeResponse {
+	t.Helper()
+
+	resp, err := http.Get(serverURL + path)
+	if err != nil {
+		t.Fatalf("Failed to get %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		t.Errorf("Expected status %d from %s, got %d", expectedStatus, path, resp.StatusCode)
+	}
+
+	var probe models.ProbeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&probe); err != nil {
+		t.Fatalf("Failed to decode %s: %v", path, err)
+	}
+	return probe
+}
+
+// findCheck returns the named check, or a zero check when it is missing
+func findCheck(checks []models.HealthCheck, name string) models.HealthCheck {
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return models.HealthCheck{}
+}
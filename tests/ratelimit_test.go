@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/ratelimit"
+)
+
+// TestRateLimit_PerKey tests that each API key has its own publish budget
+func TestRateLimit_PerKey(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, []string{"key-a", "key-b"}, func(cfg *config.Config) {
+		cfg.PublishRateLimitPerKey = 2
+	})
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "key-a").Body.Close()
+
+	connA := ConnectWebSocket(t, server.WSURL, "client-a")
+	defer connA.Close()
+	authenticateWithKey(t, connA, "key-a")
+
+	connB := ConnectWebSocket(t, server.WSURL, "client-b")
+	defer connB.Close()
+	authenticateWithKey(t, connB, "key-b")
+
+	for _, requestID := range []string{"pub-1", "pub-2"} {
+		Publish(t, connA, "orders", uuid.NewString(), "within burst", requestID)
+		if msg := WaitForAck(t, connA, requestID, 2*time.Second); msg.Status != "ok" {
+			t.Fatalf("Expected publish within burst to succeed, got %s", msg.Status)
+		}
+	}
+	Publish(t, connA, "orders", uuid.NewString(), "throttled", "pub-3")
+	expectRateLimited(t, connA, "pub-3")
+
+	// Another key is unaffected
+	Publish(t, connB, "orders", uuid.NewString(), "other key", "pub-4")
+	if msg := WaitForAck(t, connB, "pub-4", 2*time.Second); msg.Status != "ok" {
+		t.Errorf("Expected publish with another key to succeed, got %s", msg.Status)
+	}
+
+	// The budget refills over time
+	time.Sleep(600 * time.Millisecond)
+	Publish(t, connA, "orders", uuid.NewString(), "refilled", "pub-5")
+	if msg := WaitForAck(t, connA, "pub-5", 2*time.Second); msg.Status != "ok" {
+		t.Errorf("Expected publish after refill to succeed, got %s", msg.Status)
+	}
+}
+
+// TestRateLimit_TopicBytes tests that payload bytes per topic are limited
+func TestRateLimit_TopicBytes(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.PublishByteLimitPerTopic = 100
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+	CreateTopic(t, server.URL, "payments").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "bytes-client")
+	defer conn.Close()
+
+	payload := strings.Repeat("x", 70)
+	Publish(t, conn, "orders", uuid.NewString(), payload, "pub-1")
+	if msg := WaitForAck(t, conn, "pub-1", 2*time.Second); msg.Status != "ok" {
+		t.Fatalf("Expected first publish to succeed, got %s", msg.Status)
+	}
+	Publish(t, conn, "orders", uuid.NewString(), payload, "pub-2")
+	expectRateLimited(t, conn, "pub-2")
+
+	// Other topics have their own budget
+	Publish(t, conn, "payments", uuid.NewString(), payload, "pub-3")
+	if msg := WaitForAck(t, conn, "pub-3", 2*time.Second); msg.Status != "ok" {
+		t.Errorf("Expected publish to another topic to succeed, got %s", msg.Status)
+	}
+}
+
+// TestRateLimit_ConnectionAttempts tests that connection attempts per IP are rejected with 429
+func TestRateLimit_ConnectionAttempts(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.ConnectRateLimitPerIP = 2
+	})
+	defer cleanup()
+
+	for _, clientID := range []string{"conn-1", "conn-2"} {
+		conn := ConnectWebSocket(t, server.WSURL, clientID)
+		defer conn.Close()
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(server.WSURL+"/ws?client_id=conn-3", nil)
+	if err == nil {
+		t.Fatal("Expected connection attempt over the limit to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %v", resp)
+	}
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || retryAfter < 1 {
+		t.Errorf("Expected Retry-After in seconds, got %q", resp.Header.Get("Retry-After"))
+	}
+}
+
+// TestRateLimit_ConcurrentPublishes tests that concurrent publishes cannot overdraw a limit
+func TestRateLimit_ConcurrentPublishes(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		PublishGlobal: ratelimit.Rule{Messages: 20},
+		PublishPerKey: ratelimit.Rule{Messages: 1000},
+	})
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			if ok, _ := limiter.AllowPublish("key-"+strconv.Itoa(i%4), "orders", 10); ok {
+				allowed.Add(1)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// Refill during the test can add a token or two, but never a second burst
+	if n := allowed.Load(); n < 20 || n > 22 {
+		t.Errorf("Expected about 20 publishes within the global burst, got %d", n)
+	}
+}
+
+// TestRateLimit_UpdateKeepsUsage tests that replacing the limits does not refill spent budgets
+func TestRateLimit_UpdateKeepsUsage(t *testing.T) {
+	cfg := ratelimit.Config{PublishPerKey: ratelimit.Rule{Messages: 2}}
+	limiter := ratelimit.New(cfg)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.AllowPublish("key-a", "orders", 10); !ok {
+			t.Fatalf("Expected publish %d within burst to succeed", i+1)
+		}
+	}
+
+	// Applying the same limits again, as a reload of an unrelated setting does
+	limiter.Update(cfg)
+	if ok, _ := limiter.AllowPublish("key-a", "orders", 10); ok {
+		t.Error("Expected unchanged limits to keep the spent budget")
+	}
+
+	// Raising the limit starts from the tokens left, not a full bucket
+	limiter.Update(ratelimit.Config{PublishPerKey: ratelimit.Rule{Messages: 4}})
+	if ok, _ := limiter.AllowPublish("key-a", "orders", 10); ok {
+		t.Error("Expected changed limits to keep the spent budget")
+	}
+
+	// Keys without usage under the old limits get the new burst
+	for i := 0; i < 4; i++ {
+		if ok, _ := limiter.AllowPublish("key-b", "orders", 10); !ok {
+			t.Fatalf("Expected publish %d within the new burst to succeed", i+1)
+		}
+	}
+}
+
+// Helper functions for rate limit tests
+
+func expectRateLimited(t *testing.T, conn *websocket.Conn, requestID string) {
+	t.Helper()
+
+	msg := ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.RequestID != requestID || msg.Error == nil || msg.Error.Code != "RATE_LIMITED" {
+		t.Fatalf("Expected RATE_LIMITED error for %s, got %+v", requestID, msg)
+	}
+	if !strings.Contains(msg.Error.Message, "retry after") {
+		t.Errorf("Expected retry hint in error message, got %s", msg.Error.Message)
+	}
+}
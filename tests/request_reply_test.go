@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestRequestReply tests that the first reply is routed back to the requester
+func TestRequestReply(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "rpc.echo")
+
+	responder := ConnectWebSocket(t, server.WSURL, "responder-1")
+	defer responder.Close()
+	Subscribe(t, responder, "rpc.echo", 0, "sub-req")
+	WaitForAck(t, responder, "sub-req", 2*time.Second)
+
+	requester := ConnectWebSocket(t, server.WSURL, "requester-1")
+	defer requester.Close()
+	SendRequest(t, requester, "rpc.echo", "hello", 2000, "call-1")
+
+	// Responder sees the request with a private reply inbox
+	request := WaitForEvent(t, responder, 2*time.Second)
+	if request.Message.ReplyTo == "" {
+		t.Fatal("Expected request event to carry reply_to")
+	}
+
+	SendReply(t, responder, request.Message.ReplyTo, "hello back", "reply-1")
+	WaitForAck(t, responder, "reply-1", 2*time.Second)
+
+	reply := WaitForType(t, requester, "reply", 2*time.Second)
+	if reply.RequestID != "call-1" {
+		t.Errorf("Expected request_id 'call-1', got '%s'", reply.RequestID)
+	}
+	if reply.Message == nil || reply.Message.Payload != "hello back" {
+		t.Errorf("Expected reply payload 'hello back', got %+v", reply.Message)
+	}
+
+	// Only the first reply is delivered
+	SendReply(t, responder, request.Message.ReplyTo, "too late", "reply-2")
+	msg := WaitForType(t, responder, "error", 2*time.Second)
+	if msg.Error == nil || msg.Error.Code != "REQUEST_NOT_FOUND" {
+		t.Errorf("Expected REQUEST_NOT_FOUND for second reply, got %+v", msg.Error)
+	}
+}
+
+// TestRequestTimeout tests that a requester receives a typed error when nobody answers
+func TestRequestTimeout(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "rpc.silent")
+
+	responder := ConnectWebSocket(t, server.WSURL, "responder-1")
+	defer responder.Close()
+	Subscribe(t, responder, "rpc.silent", 0, "sub-req")
+	WaitForAck(t, responder, "sub-req", 2*time.Second)
+
+	requester := ConnectWebSocket(t, server.WSURL, "requester-1")
+	defer requester.Close()
+	SendRequest(t, requester, "rpc.silent", "anyone?", 200, "call-1")
+
+	msg := WaitForType(t, requester, "error", 2*time.Second)
+	if msg.RequestID != "call-1" {
+		t.Errorf("Expected request_id 'call-1', got '%s'", msg.RequestID)
+	}
+	if msg.Error == nil || msg.Error.Code != "REQUEST_TIMEOUT" {
+		t.Errorf("Expected REQUEST_TIMEOUT error, got %+v", msg.Error)
+	}
+}
+
+// TestRequestNoResponders tests that requests to a topic without subscribers fail fast
+func TestRequestNoResponders(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "rpc.empty")
+
+	requester := ConnectWebSocket(t, server.WSURL, "requester-1")
+	defer requester.Close()
+	SendRequest(t, requester, "rpc.empty", "anyone?", 0, "call-1")
+
+	msg := WaitForType(t, requester, "error", 2*time.Second)
+	if msg.Error == nil || msg.Error.Code != "NO_RESPONDERS" {
+		t.Errorf("Expected NO_RESPONDERS error, got %+v", msg.Error)
+	}
+}
+
+// TestRequestDuplicate tests that a repeated request message ID gets a duplicate ack, like a publish
+func TestRequestDuplicate(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "rpc.echo")
+
+	responder := ConnectWebSocket(t, server.WSURL, "responder-1")
+	defer responder.Close()
+	Subscribe(t, responder, "rpc.echo", 0, "sub-req")
+	WaitForAck(t, responder, "sub-req", 2*time.Second)
+
+	requester := ConnectWebSocket(t, server.WSURL, "requester-1")
+	defer requester.Close()
+
+	messageID := uuid.New().String()
+	for _, requestID := range []string{"call-1", "call-2"} {
+		SendMessage(t, requester, models.ClientMessage{
+			Type:      "request",
+			Topic:     "rpc.echo",
+			Message:   &models.Message{ID: messageID, Payload: "hello"},
+			TimeoutMs: 2000,
+			RequestID: requestID,
+		})
+	}
+
+	if msg := WaitForAck(t, requester, "call-2", 2*time.Second); msg.Status != "duplicate" {
+		t.Errorf("Expected duplicate ack for repeated request, got %s", msg.Status)
+	}
+
+	WaitForEvent(t, responder, 2*time.Second)
+	if msg, err := ReceiveMessageNoFail(responder, 300*time.Millisecond); err == nil {
+		t.Errorf("Expected the repeated request not to reach the responder, got %+v", msg)
+	}
+}
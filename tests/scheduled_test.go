@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestDelayedPublish tests that a delayed message is acked immediately but delivered later
+func TestDelayedPublish(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "reminders")
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	Subscribe(t, sub, "reminders", 0, "sub-req")
+	WaitForAck(t, sub, "sub-req", 2*time.Second)
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	messageID := uuid.New().String()
+	PublishDelayed(t, pub, "reminders", messageID, "later", 500, "pub-req")
+
+	ack := WaitForAck(t, pub, "pub-req", 2*time.Second)
+	if ack.Status != "scheduled" {
+		t.Errorf("Expected status 'scheduled', got '%s'", ack.Status)
+	}
+
+	// Nothing should arrive before the delay elapses
+	if msg, err := ReceiveMessageNoFail(sub, 200*time.Millisecond); err == nil {
+		t.Fatalf("Received message before delivery time: %+v", msg)
+	}
+
+	sub2 := ConnectWebSocket(t, server.WSURL, "subscriber-2")
+	defer sub2.Close()
+	Subscribe(t, sub2, "reminders", 0, "sub-req-2")
+	WaitForAck(t, sub2, "sub-req-2", 2*time.Second)
+
+	event := WaitForEvent(t, sub2, 2*time.Second)
+	if event.Message == nil || event.Message.ID != messageID {
+		t.Fatalf("Expected scheduled message %s, got %+v", messageID, event.Message)
+	}
+}
+
+// TestListAndCancelScheduled tests listing and cancelling scheduled messages via REST
+func TestListAndCancelScheduled(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "reminders")
+	CreateTopic(t, server.URL, "other")
+
+	sub := ConnectWebSocket(t, server.WSURL, "subscriber-1")
+	defer sub.Close()
+	Subscribe(t, sub, "reminders", 0, "sub-req")
+	WaitForAck(t, sub, "sub-req", 2*time.Second)
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	messageID := uuid.New().String()
+	PublishDelayed(t, pub, "reminders", messageID, "later", 300, "pub-req-1")
+	WaitForAck(t, pub, "pub-req-1", 2*time.Second)
+
+	PublishDelayed(t, pub, "other", uuid.New().String(), "elsewhere", 60000, "pub-req-2")
+	WaitForAck(t, pub, "pub-req-2", 2*time.Second)
+
+	scheduled := ListScheduled(t, server.URL, "reminders")
+	if len(scheduled) != 1 {
+		t.Fatalf("Expected 1 scheduled message for topic, got %d", len(scheduled))
+	}
+	if scheduled[0].ID != messageID {
+		t.Errorf("Expected scheduled ID %s, got %s", messageID, scheduled[0].ID)
+	}
+
+	if all := ListScheduled(t, server.URL, ""); len(all) != 2 {
+		t.Errorf("Expected 2 scheduled messages in total, got %d", len(all))
+	}
+
+	resp := CancelScheduled(t, server.URL, messageID)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Cancelled message must never be delivered
+	if msg, err := ReceiveMessageNoFail(sub, 600*time.Millisecond); err == nil {
+		t.Fatalf("Received cancelled message: %+v", msg)
+	}
+
+	resp = CancelScheduled(t, server.URL, messageID)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for second cancel, got %d", resp.StatusCode)
+	}
+}
+
+// TestScheduledPublishValidation tests rejection of invalid scheduling options
+func TestScheduledPublishValidation(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "reminders")
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	SendMessage(t, pub, models.ClientMessage{
+		Type:      "publish",
+		Topic:     "reminders",
+		Message:   &models.Message{ID: uuid.New().String(), Payload: "bad"},
+		DeliverAt: "tomorrow",
+		RequestID: "bad-time",
+	})
+
+	msg := ReceiveMessage(t, pub, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "BAD_REQUEST" {
+		t.Fatalf("Expected BAD_REQUEST error, got %+v", msg)
+	}
+}
+
+// TestScheduledRetryAfterRejection tests that a message ID is not remembered as a duplicate
+// when scheduling it fails or it is cancelled
+func TestScheduledRetryAfterRejection(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.MaxScheduledMessages = 1
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "reminders").Body.Close()
+
+	pub := ConnectWebSocket(t, server.WSURL, "publisher-1")
+	defer pub.Close()
+
+	first, second := uuid.New().String(), uuid.New().String()
+	PublishDelayed(t, pub, "reminders", first, "first", 60000, "pub-req-1")
+	if ack := WaitForAck(t, pub, "pub-req-1", 2*time.Second); ack.Status != "scheduled" {
+		t.Fatalf("Expected status 'scheduled', got '%s'", ack.Status)
+	}
+
+	// The scheduler is full, so the second message is refused
+	PublishDelayed(t, pub, "reminders", second, "second", 60000, "pub-req-2")
+	if msg := ReceiveMessage(t, pub, 2*time.Second); msg.Type != "error" {
+		t.Fatalf("Expected error while the scheduler is full, got %+v", msg)
+	}
+
+	resp := CancelScheduled(t, server.URL, first)
+	resp.Body.Close()
+
+	// Neither the refused nor the cancelled message is suppressed as a duplicate
+	PublishDelayed(t, pub, "reminders", second, "second", 60000, "pub-req-3")
+	if ack := WaitForAck(t, pub, "pub-req-3", 2*time.Second); ack.Status != "scheduled" {
+		t.Errorf("Expected retried message to be scheduled, got '%s'", ack.Status)
+	}
+	CancelScheduled(t, server.URL, second).Body.Close()
+
+	PublishDelayed(t, pub, "reminders", first, "first again", 60000, "pub-req-4")
+	if ack := WaitForAck(t, pub, "pub-req-4", 2*time.Second); ack.Status != "scheduled" {
+		t.Errorf("Expected cancelled message to be schedulable again, got '%s'", ack.Status)
+	}
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/pubsub"
+)
+
+// TestShutdown_Drain tests that queued events are flushed after a shutdown notice and before a going-away close
+func TestShutdown_Drain(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.SubscriberQueue = 50
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	subscriber := ConnectWebSocket(t, server.WSURL, "subscriber")
+	defer subscriber.Close()
+	Subscribe(t, subscriber, "orders", 0, "sub-1")
+	WaitForAck(t, subscriber, "sub-1", 2*time.Second)
+
+	publisher := ConnectWebSocket(t, server.WSURL, "publisher")
+	defer publisher.Close()
+
+	// Large events back up in the subscriber's queue while it is not reading
+	payload := strings.Repeat("x", 64*1024)
+	for i := 0; i < 40; i++ {
+		Publish(t, publisher, "orders", uuid.New().String(), payload, "pub")
+	}
+	waitForSubscriptionStats(t, server.URL, "orders", func(subs []models.SubscriptionStats) bool {
+		return len(subs) == 1 && subs[0].Delivered+int64(subs[0].QueueDepth) == 40
+	})
+
+	done := make(chan struct{})
+	go func() {
+		server.engine.Shutdown()
+		close(done)
+	}()
+
+	events, notices := 0, 0
+	for {
+		msg, err := ReceiveMessageNoFail(subscriber, 5*time.Second)
+		if err != nil {
+			closeErr, ok := err.(*websocket.CloseError)
+			if !ok || closeErr.Code != websocket.CloseGoingAway || closeErr.Text != "server shutting down" {
+				t.Fatalf("Expected going-away close frame, got %v", err)
+			}
+			break
+		}
+		switch {
+		case msg.Type == "event":
+			events++
+		case msg.Type == "info" && msg.Msg == "shutdown":
+			notices++
+			if msg.ReconnectAfterMs < 1000 || msg.ReconnectAfterMs >= 5000 {
+				t.Errorf("Expected reconnect hint between 1s and 5s, got %dms", msg.ReconnectAfterMs)
+			}
+		}
+	}
+	if events != 40 || notices != 1 {
+		t.Errorf("Expected 40 events and 1 shutdown notice, got %d events and %d notices", events, notices)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return after clients drained")
+	}
+	expectMetrics(t, server.URL, "", `pubsub_client_disconnects_total{reason="shutdown"} 2`)
+}
+
+// TestShutdown_RefusesNewWork tests that connections and publishes are refused once shutdown begins
+func TestShutdown_RefusesNewWork(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+	server.engine.Shutdown()
+
+	expectRejectedUpgrade(t, server.WSURL, "late", http.StatusServiceUnavailable)
+
+	msg := models.Message{ID: uuid.New().String(), Payload: "hello"}
+	if err := server.engine.Publish("orders", msg); err != pubsub.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown from publish, got %v", err)
+	}
+	if _, err := server.engine.Schedule("orders", msg, time.Now().Add(time.Minute)); err != pubsub.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown from schedule, got %v", err)
+	}
+}
+
+// TestShutdown_DrainTimeout tests that clients that do not drain in time are closed when the timeout expires
+func TestShutdown_DrainTimeout(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.SubscriberQueue = 200
+		cfg.ShutdownTimeout = 200 * time.Millisecond
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	subscriber := ConnectWebSocket(t, server.WSURL, "subscriber")
+	defer subscriber.Close()
+	Subscribe(t, subscriber, "orders", 0, "sub-1")
+	WaitForAck(t, subscriber, "sub-1", 2*time.Second)
+
+	// More than the connection buffers hold, so writes block while the subscriber is not reading
+	publisher := ConnectWebSocket(t, server.WSURL, "publisher")
+	defer publisher.Close()
+	payload := strings.Repeat("x", 64*1024)
+	for i := 0; i < 150; i++ {
+		Publish(t, publisher, "orders", uuid.New().String(), payload, "pub")
+	}
+
+	start := time.Now()
+	server.engine.Shutdown()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown to give up draining after the timeout, took %v", elapsed)
+	}
+
+	// The subscriber reads what was written before the connection was closed
+	for {
+		_, err := ReceiveMessageNoFail(subscriber, 2*time.Second)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			t.Fatal("Expected connection to be closed after shutdown")
+		}
+		if err != nil {
+			break
+		}
+	}
+}
+
+// TestShutdown_ContextDeadline tests that the drain stops at the caller's deadline rather than the configured timeout
+func TestShutdown_ContextDeadline(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.SubscriberQueue = 200
+		cfg.ShutdownTimeout = time.Minute
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	subscriber := ConnectWebSocket(t, server.WSURL, "subscriber")
+	defer subscriber.Close()
+	Subscribe(t, subscriber, "orders", 0, "sub-1")
+	WaitForAck(t, subscriber, "sub-1", 2*time.Second)
+
+	// Enough to block writes while the subscriber is not reading
+	publisher := ConnectWebSocket(t, server.WSURL, "publisher")
+	defer publisher.Close()
+	payload := strings.Repeat("x", 64*1024)
+	for i := 0; i < 150; i++ {
+		Publish(t, publisher, "orders", uuid.New().String(), payload, "pub")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	server.engine.ShutdownContext(ctx)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown to stop draining at the context deadline, took %v", elapsed)
+	}
+}
+
+// TestShutdown_ConnectDuringDrain tests that clients arriving while others drain are turned away instead of waiting out the deadline
+func TestShutdown_ConnectDuringDrain(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.SubscriberQueue = 200
+		cfg.ShutdownTimeout = time.Second
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	// A subscriber that does not read keeps the drain running until the deadline
+	subscriber := ConnectWebSocket(t, server.WSURL, "subscriber")
+	defer subscriber.Close()
+	Subscribe(t, subscriber, "orders", 0, "sub-1")
+	WaitForAck(t, subscriber, "sub-1", 2*time.Second)
+
+	publisher := ConnectWebSocket(t, server.WSURL, "publisher")
+	defer publisher.Close()
+	payload := strings.Repeat("x", 64*1024)
+	for i := 0; i < 150; i++ {
+		Publish(t, publisher, "orders", uuid.New().String(), payload, "pub")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.engine.Shutdown()
+		close(done)
+	}()
+	for !server.engine.IsShuttingDown() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(server.WSURL+"/ws?client_id=late", nil)
+	if err == nil {
+		t.Fatal("Expected connection during drain to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %v", resp)
+	}
+
+	// A client that passed the upgrade check before the drain began is refused at registration
+	late := pubsub.NewSubscriber("late-upgrade", nil)
+	if err := server.engine.RegisterClient(late); err != pubsub.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown from registration during drain, got %v", err)
+	}
+	if _, err := server.engine.GetClient("late-upgrade"); err == nil {
+		t.Error("Expected client refused during drain not to be registered")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected shutdown to finish at the drain deadline")
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestSubscriptionStats_Delivery tests per-subscription delivery counters in /stats
+func TestSubscriptionStats_Delivery(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	consumer := ConnectWebSocket(t, server.WSURL, "consumer")
+	defer consumer.Close()
+	Subscribe(t, consumer, "orders", 0, "sub-1")
+	WaitForAck(t, consumer, "sub-1", 2*time.Second)
+
+	publisher := ConnectWebSocket(t, server.WSURL, "publisher")
+	defer publisher.Close()
+	for i := 0; i < 3; i++ {
+		Publish(t, publisher, "orders", uuid.New().String(), i, "pub")
+		WaitForEvent(t, consumer, 2*time.Second)
+	}
+
+	// Subscribing later does not count earlier messages as lag; replayed history counts as delivered
+	late := ConnectWebSocket(t, server.WSURL, "late")
+	defer late.Close()
+	Subscribe(t, late, "orders", 2, "sub-2")
+	WaitForEvent(t, late, 2*time.Second)
+	WaitForEvent(t, late, 2*time.Second)
+
+	subscriptions := waitForSubscriptionStats(t, server.URL, "orders", func(stats []models.SubscriptionStats) bool {
+		return len(stats) == 2 && stats[0].Delivered == 3 && stats[1].Delivered == 2
+	})
+	for _, stats := range subscriptions {
+		if stats.Lag != 0 || stats.Dropped != 0 || stats.QueueDepth != 0 || stats.LastDelivery == "" {
+			t.Errorf("Expected caught-up subscription, got %+v", stats)
+		}
+	}
+	if subscriptions[0].ClientID != "consumer" || subscriptions[1].ClientID != "late" {
+		t.Errorf("Expected subscriptions sorted by client ID, got %+v", subscriptions)
+	}
+
+	Unsubscribe(t, late, "orders", "unsub-1")
+	WaitForAck(t, late, "unsub-1", 2*time.Second)
+	if stats := GetStats(t, server.URL).Topics["orders"].Subscriptions; len(stats) != 1 {
+		t.Errorf("Expected unsubscribed client to be removed, got %+v", stats)
+	}
+}
+
+// TestSubscriptionStats_Lag tests that a consumer that stops reading shows lag and drops
+func TestSubscriptionStats_Lag(t *testing.T) {
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.SubscriberQueue = 10
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	// The stalled consumer never reads, so writes stop once the socket buffers fill
+	stalled := ConnectWebSocket(t, server.WSURL, "stalled")
+	defer stalled.Close()
+	Subscribe(t, stalled, "orders", 0, "sub-1")
+	WaitForAck(t, stalled, "sub-1", 2*time.Second)
+
+	publisher := ConnectWebSocket(t, server.WSURL, "publisher")
+	defer publisher.Close()
+	payload := strings.Repeat("x", 64*1024)
+	const published = 200
+	for i := 0; i < published; i++ {
+		Publish(t, publisher, "orders", uuid.New().String(), payload, "pub")
+	}
+
+	subscriptions := waitForSubscriptionStats(t, server.URL, "orders", func(stats []models.SubscriptionStats) bool {
+		return len(stats) == 1 && stats[0].Delivered+stats[0].Dropped+int64(stats[0].QueueDepth) >= published-1
+	})
+	stats := subscriptions[0]
+	if stats.Dropped == 0 || stats.Lag == 0 {
+		t.Errorf("Expected stalled consumer to drop messages and lag, got %+v", stats)
+	}
+	// Lag covers the queued events and those dropped since the last write,
+	// but not events dropped before a later one was written
+	if stats.Lag < int64(stats.QueueDepth) || stats.Lag > published-stats.Delivered {
+		t.Errorf("Expected lag between %d and %d, got %+v", stats.QueueDepth, published-stats.Delivered, stats)
+	}
+}
+
+// Helper functions for subscription stats tests
+
+// waitForSubscriptionStats polls /stats until ready accepts a topic's subscription stats
+func waitForSubscriptionStats(t *testing.T, serverURL, topic string, ready func([]models.SubscriptionStats) bool) []models.SubscriptionStats {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := GetStats(t, serverURL).Topics[topic].Subscriptions
+		if ready(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Unexpected subscription stats for %s: %+v", topic, stats)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/tenant"
+)
+
+// testTenants defines two teams sharing a server; "beta" has tight limits
+var testTenants = tenant.Config{
+	Tenants: []tenant.Tenant{
+		{Name: "alpha", APIKeys: []string{"alpha-key"}},
+		{Name: "beta", APIKeys: []string{"beta-key"}, Limits: tenant.Limits{
+			MaxTopics:       2,
+			MaxConnections:  1,
+			MaxPublishRate:  1,
+			MaxPublishBurst: 2,
+		}},
+	},
+}
+
+// TestTenants_TopicIsolation tests that tenants can use the same topic names and only see their own topics
+func TestTenants_TopicIsolation(t *testing.T) {
+	server, cleanup := SetupTestServerWithTenants(t, writeTenants(t, testTenants))
+	defer cleanup()
+
+	for _, key := range []string{"alpha-key", "beta-key"} {
+		resp := CreateTopicWithAuth(t, server.URL, "orders", key)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201 for %s, got %d", key, resp.StatusCode)
+		}
+	}
+	CreateTopicWithAuth(t, server.URL, "alpha-only", "alpha-key").Body.Close()
+
+	alphaTopics := ListTopicsWithAuth(t, server.URL, "alpha-key")
+	if len(alphaTopics) != 2 {
+		t.Errorf("Expected 2 topics for alpha, got %d", len(alphaTopics))
+	}
+	betaTopics := ListTopicsWithAuth(t, server.URL, "beta-key")
+	if len(betaTopics) != 1 || betaTopics[0].Name != "orders" {
+		t.Errorf("Expected only orders for beta, got %+v", betaTopics)
+	}
+
+	resp := makeGetRequest(t, server.URL+"/stats", "beta-key")
+	var stats models.StatsResponse
+	json.NewDecoder(resp.Body).Decode(&stats)
+	resp.Body.Close()
+	if _, exists := stats.Topics["alpha-only"]; exists || len(stats.Topics) != 1 {
+		t.Errorf("Expected stats for beta's topics only, got %+v", stats.Topics)
+	}
+
+	// Deleting beta's topic leaves alpha's topic of the same name alone
+	resp = deleteTopicWithAuth(t, server.URL, "orders", "beta-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 deleting beta's topic, got %d", resp.StatusCode)
+	}
+	if topics := ListTopicsWithAuth(t, server.URL, "alpha-key"); len(topics) != 2 {
+		t.Errorf("Expected alpha to keep 2 topics, got %d", len(topics))
+	}
+
+	resp = CreateTopicWithAuth(t, server.URL, "beta/orders", "alpha-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for topic name with '/', got %d", resp.StatusCode)
+	}
+}
+
+// TestTenants_NoCrossTenantDelivery tests that messages stay within the publisher's tenant
+func TestTenants_NoCrossTenantDelivery(t *testing.T) {
+	server, cleanup := SetupTestServerWithTenants(t, writeTenants(t, testTenants))
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "alpha-key").Body.Close()
+	CreateTopicWithAuth(t, server.URL, "orders", "beta-key").Body.Close()
+
+	alpha := ConnectWebSocket(t, server.WSURL, "alpha-client")
+	defer alpha.Close()
+	authenticateWithKey(t, alpha, "alpha-key")
+
+	beta := ConnectWebSocket(t, server.WSURL, "beta-client")
+	defer beta.Close()
+	authenticateWithKey(t, beta, "beta-key")
+
+	Subscribe(t, alpha, "orders", 0, "sub-alpha")
+	WaitForAck(t, alpha, "sub-alpha", 2*time.Second)
+	Subscribe(t, beta, "orders", 0, "sub-beta")
+	WaitForAck(t, beta, "sub-beta", 2*time.Second)
+
+	Publish(t, alpha, "orders", uuid.NewString(), "for alpha", "pub-1")
+
+	event := WaitForEvent(t, alpha, 2*time.Second)
+	if event.Topic != "orders" {
+		t.Errorf("Expected event on orders, got %s", event.Topic)
+	}
+	if msg, err := ReceiveMessageNoFail(beta, 500*time.Millisecond); err == nil {
+		t.Errorf("Expected no event for beta, got %+v", msg)
+	}
+}
+
+// TestTenants_ClientIDTakeover tests that a client in one tenant cannot reuse a connected client's ID from another
+func TestTenants_ClientIDTakeover(t *testing.T) {
+	server, cleanup := SetupTestServerWithTenants(t, writeTenants(t, testTenants))
+	defer cleanup()
+
+	CreateTopicWithAuth(t, server.URL, "orders", "alpha-key").Body.Close()
+
+	alpha := ConnectWebSocket(t, server.WSURL, "shared-id")
+	defer alpha.Close()
+	authenticateWithKey(t, alpha, "alpha-key")
+	Subscribe(t, alpha, "orders", 0, "sub-alpha")
+	WaitForAck(t, alpha, "sub-alpha", 2*time.Second)
+
+	_, resp, err := websocket.DefaultDialer.Dial(server.WSURL+"/ws?client_id=shared-id", nil)
+	if err == nil {
+		t.Fatal("Expected connection with a client ID in use to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %v", resp)
+	}
+
+	// The original client keeps its registration and its deliveries
+	publisher := ConnectWebSocket(t, server.WSURL, "alpha-publisher")
+	defer publisher.Close()
+	authenticateWithKey(t, publisher, "alpha-key")
+	Publish(t, publisher, "orders", uuid.NewString(), "for alpha", "pub-1")
+
+	if event := WaitForEvent(t, alpha, 2*time.Second); event.Topic != "orders" {
+		t.Errorf("Expected event on orders, got %s", event.Topic)
+	}
+}
+
+// TestTenants_Limits tests that per-tenant topic, connection and publish rate limits are enforced
+func TestTenants_Limits(t *testing.T) {
+	server, cleanup := SetupTestServerWithTenants(t, writeTenants(t, testTenants))
+	defer cleanup()
+
+	// Topic limit
+	CreateTopicWithAuth(t, server.URL, "one", "beta-key").Body.Close()
+	CreateTopicWithAuth(t, server.URL, "two", "beta-key").Body.Close()
+	resp := CreateTopicWithAuth(t, server.URL, "three", "beta-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 over the topic limit, got %d", resp.StatusCode)
+	}
+	resp = CreateTopicWithAuth(t, server.URL, "three", "alpha-key")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected other tenants to be unaffected, got %d", resp.StatusCode)
+	}
+
+	// Publish rate limit: the burst is allowed, then publishing is throttled
+	conn := ConnectWebSocket(t, server.WSURL, "beta-1")
+	defer conn.Close()
+	authenticateWithKey(t, conn, "beta-key")
+
+	for i, requestID := range []string{"pub-1", "pub-2"} {
+		Publish(t, conn, "one", uuid.NewString(), i, requestID)
+		if msg := WaitForAck(t, conn, requestID, 2*time.Second); msg.Status != "ok" {
+			t.Fatalf("Expected publish within burst to succeed, got %s", msg.Status)
+		}
+	}
+	Publish(t, conn, "one", uuid.NewString(), "throttled", "pub-3")
+	msg := ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "RATE_LIMITED" {
+		t.Errorf("Expected RATE_LIMITED error, got %+v", msg)
+	}
+
+	// Connection limit
+	second := ConnectWebSocket(t, server.WSURL, "beta-2")
+	defer second.Close()
+	SendMessage(t, second, models.ClientMessage{Type: "auth", APIKey: "beta-key", RequestID: "auth-1"})
+	expectClosedWith(t, second, "TENANT_LIMIT_EXCEEDED")
+}
+
+// TestTenants_InvalidConfig tests that invalid tenant files are rejected at load time
+func TestTenants_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  tenant.Config
+	}{
+		{
+			name: "invalid name",
+			cfg:  tenant.Config{Tenants: []tenant.Tenant{{Name: "a/b"}}},
+		},
+		{
+			name: "shared key",
+			cfg: tenant.Config{Tenants: []tenant.Tenant{
+				{Name: "a", APIKeys: []string{"shared"}},
+				{Name: "b", APIKeys: []string{"shared"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tenant.LoadRegistry(writeTenants(t, tt.cfg)); err == nil {
+				t.Error("Expected tenants file to be rejected")
+			}
+		})
+	}
+}
+
+// Helper functions for tenant tests
+
+func writeTenants(t *testing.T, cfg tenant.Config) string {
+	t.Helper()
+
+	data, _ := json.Marshal(cfg)
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write tenants file: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/internal/models"
+)
+
+// TestThroughput_Bytes tests payload byte counters per topic, per tenant and engine-wide
+func TestThroughput_Bytes(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+	CreateTopic(t, server.URL, "idle").Body.Close()
+
+	first := ConnectWebSocket(t, server.WSURL, "first")
+	defer first.Close()
+	second := ConnectWebSocket(t, server.WSURL, "second")
+	defer second.Close()
+	for _, conn := range []*websocket.Conn{first, second} {
+		Subscribe(t, conn, "orders", 0, "sub-1")
+		WaitForAck(t, conn, "sub-1", 2*time.Second)
+	}
+
+	// "hello" encodes to 7 bytes and is queued for both subscribers
+	for i := 0; i < 3; i++ {
+		Publish(t, first, "orders", uuid.New().String(), "hello", "pub")
+		WaitForEvent(t, second, 2*time.Second)
+	}
+
+	stats := GetStats(t, server.URL)
+	orders := stats.Topics["orders"].Throughput
+	if orders.BytesIn != 21 || orders.BytesOut != 42 {
+		t.Errorf("Expected 21 bytes in and 42 out for orders, got %+v", orders)
+	}
+	if idle := stats.Topics["idle"].Throughput; idle.BytesIn != 0 || idle.BytesOut != 0 {
+		t.Errorf("Expected no traffic on idle topic, got %+v", idle)
+	}
+	if stats.Throughput.BytesIn != 21 || stats.Throughput.BytesOut != 42 {
+		t.Errorf("Expected totals to sum the topics, got %+v", stats.Throughput)
+	}
+
+	if health := GetHealth(t, server.URL); health.Throughput.BytesIn != 21 || health.Throughput.BytesOut != 42 {
+		t.Errorf("Expected engine-wide bytes in health, got %+v", health.Throughput)
+	}
+}
+
+// TestThroughput_Rates tests that moving average rates are reported after the first interval
+func TestThroughput_Rates(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "client")
+	defer conn.Close()
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+
+	for i := 0; i < 10; i++ {
+		Publish(t, conn, "orders", uuid.New().String(), i, "pub")
+		WaitForEvent(t, conn, 2*time.Second)
+	}
+
+	// Rates are updated every 5 seconds and seeded from the first interval: 10 messages / 5s
+	deadline := time.Now().Add(8 * time.Second)
+	var throughput models.ThroughputStats
+	for time.Now().Before(deadline) {
+		throughput = GetStats(t, server.URL).Topics["orders"].Throughput
+		if throughput.PublishRate.M1 > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	expected := models.Rates{M1: 2, M5: 2, M15: 2}
+	if throughput.PublishRate != expected || throughput.DeliveryRate != expected {
+		t.Errorf("Expected publish and delivery rates of %+v, got %+v", expected, throughput)
+	}
+	if throughput.BytesInRate.M1 <= 0 || throughput.BytesInRate != throughput.BytesOutRate {
+		t.Errorf("Expected equal byte rates in and out, got %+v", throughput)
+	}
+	if health := GetHealth(t, server.URL); health.Throughput.PublishRate != expected {
+		t.Errorf("Expected engine-wide publish rate of %+v, got %+v", expected, health.Throughput.PublishRate)
+	}
+}
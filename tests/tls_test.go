@@ -0,0 +1,423 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/auth"
+	"github.com/tarunm/pubsub-system/internal/tlsconfig"
+)
+
+// TestTLS_HTTPS tests that REST and WebSocket endpoints are served over TLS
+func TestTLS_HTTPS(t *testing.T) {
+	pki := newTestPKI(t)
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.TLSCertFile, cfg.TLSKeyFile = pki.writeServerCert(t, 1)
+	})
+	defer cleanup()
+
+	client := pki.httpClient(nil)
+	resp, err := client.Get(server.URL + "/health")
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	conn, _, err := pki.wsDialer(nil).Dial(server.WSURL+"/ws?client_id=tls-client", nil)
+	if err != nil {
+		t.Fatalf("WSS dial failed: %v", err)
+	}
+	conn.Close()
+
+	// Plain HTTP is not served on the TLS port
+	if resp, err := http.Get("http" + server.URL[len("https"):] + "/health"); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected plain HTTP request to fail")
+		}
+	}
+}
+
+// TestTLS_MinVersion tests that clients below the minimum TLS version are rejected
+func TestTLS_MinVersion(t *testing.T) {
+	pki := newTestPKI(t)
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.TLSCertFile, cfg.TLSKeyFile = pki.writeServerCert(t, 1)
+		cfg.TLSMinVersion = "1.3"
+	})
+	defer cleanup()
+
+	conn, err := tls.Dial("tcp", server.URL[len("https://"):], &tls.Config{
+		RootCAs:    pki.pool,
+		MaxVersion: tls.VersionTLS12,
+	})
+	if err == nil {
+		conn.Close()
+		t.Fatal("Expected TLS 1.2 handshake to be rejected")
+	}
+
+	conn, err = tls.Dial("tcp", server.URL[len("https://"):], &tls.Config{RootCAs: pki.pool})
+	if err != nil {
+		t.Fatalf("Expected TLS 1.3 handshake to succeed, got %v", err)
+	}
+	if version := conn.ConnectionState().Version; version != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3, got %x", version)
+	}
+	conn.Close()
+}
+
+// TestTLS_ALPN tests that the protocols the HTTP server offers are negotiated through the reloading configuration
+func TestTLS_ALPN(t *testing.T) {
+	pki := newTestPKI(t)
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.TLSCertFile, cfg.TLSKeyFile = pki.writeServerCert(t, 1)
+	})
+	defer cleanup()
+
+	for _, protocol := range []string{"h2", "http/1.1"} {
+		conn, err := tls.Dial("tcp", server.URL[len("https://"):], &tls.Config{
+			RootCAs:    pki.pool,
+			NextProtos: []string{protocol},
+		})
+		if err != nil {
+			t.Fatalf("Expected TLS handshake offering %s to succeed, got %v", protocol, err)
+		}
+		if negotiated := conn.ConnectionState().NegotiatedProtocol; negotiated != protocol {
+			t.Errorf("Expected ALPN to negotiate %s, got %q", protocol, negotiated)
+		}
+		conn.Close()
+	}
+}
+
+// TestTLS_InvalidConfig tests that unsupported TLS settings are rejected at startup
+func TestTLS_InvalidConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	certFile, keyFile := pki.writeServerCert(t, 1)
+	caFile := pki.writeCA(t)
+
+	tests := []struct {
+		name string
+		opts tlsconfig.Options
+	}{
+		{"missing key", tlsconfig.Options{CertFile: certFile}},
+		{"missing cert file", tlsconfig.Options{CertFile: filepath.Join(t.TempDir(), "none.pem"), KeyFile: keyFile}},
+		{"min version", tlsconfig.Options{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"}},
+		{"unknown cipher", tlsconfig.Options{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}},
+		{"client auth without CA", tlsconfig.Options{CertFile: certFile, KeyFile: keyFile, ClientAuth: tlsconfig.ClientAuthRequire}},
+		{"unknown client auth", tlsconfig.Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: "sometimes"}},
+		{"CA without certificates", tlsconfig.Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile}},
+	}
+
+	for _, tt := range tests {
+		if _, err := tlsconfig.NewReloader(tt.opts); err == nil {
+			t.Errorf("%s: expected configuration to be rejected", tt.name)
+		}
+	}
+
+	valid := tlsconfig.Options{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+		ClientCAFile: caFile,
+		ClientAuth:   tlsconfig.ClientAuthOptional,
+	}
+	if _, err := tlsconfig.NewReloader(valid); err != nil {
+		t.Errorf("Expected valid configuration to load, got %v", err)
+	}
+}
+
+// TestTLS_ClientCertificate tests that verified client certificates authenticate REST and WebSocket clients
+func TestTLS_ClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	aclFile := writeACLPolicy(t, auth.ACLPolicy{Rules: []auth.ACLRule{
+		{Principals: []string{"order-service"}, Topics: []string{"orders*"}, Actions: []string{"*"}},
+	}})
+	server, cleanup := SetupTestServerWithConfig(t, []string{"api-key"}, func(cfg *config.Config) {
+		cfg.TLSCertFile, cfg.TLSKeyFile = pki.writeServerCert(t, 1)
+		cfg.TLSClientCAFile = pki.writeCA(t)
+		cfg.ACLFile = aclFile
+	})
+	defer cleanup()
+
+	// Handshakes without a client certificate fail
+	if resp, err := pki.httpClient(nil).Get(server.URL + "/health"); err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected request without client certificate to fail")
+	}
+
+	clientCert := pki.clientCert(t, "order-service")
+	client := pki.httpClient(&clientCert)
+
+	// The certificate subject is the principal; no API key is needed
+	if status := postTopic(t, client, server.URL, "orders"); status != http.StatusCreated {
+		t.Fatalf("Expected status 201 creating orders, got %d", status)
+	}
+	if status := postTopic(t, client, server.URL, "payments"); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 creating payments, got %d", status)
+	}
+
+	// WebSocket clients skip the auth message
+	conn, _, err := pki.wsDialer(&clientCert).Dial(server.WSURL+"/ws?client_id=cert-client", nil)
+	if err != nil {
+		t.Fatalf("WSS dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	if msg := WaitForAck(t, conn, "sub-1", 2*time.Second); msg.Status != "ok" {
+		t.Errorf("Expected subscribe to succeed, got %s", msg.Status)
+	}
+}
+
+// TestTLS_OptionalClientCertificate tests that clients without certificates fall back to API keys
+func TestTLS_OptionalClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	server, cleanup := SetupTestServerWithConfig(t, []string{"api-key"}, func(cfg *config.Config) {
+		cfg.TLSCertFile, cfg.TLSKeyFile = pki.writeServerCert(t, 1)
+		cfg.TLSClientCAFile = pki.writeCA(t)
+		cfg.TLSClientAuth = tlsconfig.ClientAuthOptional
+	})
+	defer cleanup()
+
+	client := pki.httpClient(nil)
+	if status := postTopic(t, client, server.URL, "no-credentials"); status != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without credentials, got %d", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/topics", nil)
+	req.Header.Set("X-API-Key", "api-key")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 with API key, got %d", resp.StatusCode)
+	}
+
+	clientCert := pki.clientCert(t, "inventory-service")
+	if status := postTopic(t, pki.httpClient(&clientCert), server.URL, "inventory"); status != http.StatusCreated {
+		t.Errorf("Expected status 201 with client certificate, got %d", status)
+	}
+}
+
+// TestTLS_Reload tests that replaced certificate files are picked up without a restart
+func TestTLS_Reload(t *testing.T) {
+	pki := newTestPKI(t)
+	certFile, keyFile := pki.writeServerCert(t, 1)
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.TLSCertFile, cfg.TLSKeyFile = certFile, keyFile
+		cfg.TLSReloadInterval = 50 * time.Millisecond
+	})
+	defer cleanup()
+
+	addr := server.URL[len("https://"):]
+	if serial := pki.serverSerial(t, addr); serial != 1 {
+		t.Fatalf("Expected serial 1, got %d", serial)
+	}
+
+	// An invalid certificate keeps the previous one in use
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if serial := pki.serverSerial(t, addr); serial != 1 {
+		t.Fatalf("Expected serial 1 after failed reload, got %d", serial)
+	}
+
+	pki.writeServerCertTo(t, 2, certFile, keyFile)
+	deadline := time.Now().Add(2 * time.Second)
+	for pki.serverSerial(t, addr) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the new certificate")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Helper functions for TLS tests
+
+// testPKI is a throwaway certificate authority for issuing server and client certificates
+type testPKI struct {
+	dir    string
+	caCert *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	caPEM  []byte
+	pool   *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+
+	key := generateKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(100),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testPKI{
+		dir:    t.TempDir(),
+		caCert: cert,
+		caKey:  key,
+		caPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pool:   pool,
+	}
+}
+
+// issue creates a certificate signed by the CA and returns PEM certificate and key
+func (p *testPKI) issue(t *testing.T, template *x509.Certificate) ([]byte, []byte) {
+	t.Helper()
+
+	key := generateKey(t)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, p.caCert, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (p *testPKI) writeCA(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(p.dir, "ca.pem")
+	if err := os.WriteFile(path, p.caPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	return path
+}
+
+func (p *testPKI) writeServerCert(t *testing.T, serial int64) (string, string) {
+	t.Helper()
+
+	certFile, keyFile := filepath.Join(p.dir, "server.pem"), filepath.Join(p.dir, "server-key.pem")
+	p.writeServerCertTo(t, serial, certFile, keyFile)
+	return certFile, keyFile
+}
+
+func (p *testPKI) writeServerCertTo(t *testing.T, serial int64, certFile, keyFile string) {
+	t.Helper()
+
+	certPEM, keyPEM := p.issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	// Write the key first so a reload between the writes fails and keeps the old pair
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write certificate file: %v", err)
+	}
+}
+
+func (p *testPKI) clientCert(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+
+	certPEM, keyPEM := p.issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load client certificate: %v", err)
+	}
+	return cert
+}
+
+func (p *testPKI) tlsConfig(clientCert *tls.Certificate) *tls.Config {
+	cfg := &tls.Config{RootCAs: p.pool}
+	if clientCert != nil {
+		cfg.Certificates = []tls.Certificate{*clientCert}
+	}
+	return cfg
+}
+
+func (p *testPKI) httpClient(clientCert *tls.Certificate) *http.Client {
+	return &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: p.tlsConfig(clientCert)},
+	}
+}
+
+func (p *testPKI) wsDialer(clientCert *tls.Certificate) *websocket.Dialer {
+	return &websocket.Dialer{
+		HandshakeTimeout: 5 * time.Second,
+		TLSClientConfig:  p.tlsConfig(clientCert),
+	}
+}
+
+// serverSerial returns the serial number of the certificate the server presents
+func (p *testPKI) serverSerial(t *testing.T, addr string) int64 {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, p.tlsConfig(nil))
+	if err != nil {
+		t.Fatalf("TLS handshake failed: %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return key
+}
+
+func postTopic(t *testing.T, client *http.Client, serverURL, topicName string) int {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"name": topicName})
+	resp, err := client.Post(serverURL+"/topics", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
@@ -0,0 +1,265 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/tarunm/pubsub-system/config"
+	"github.com/tarunm/pubsub-system/internal/models"
+	"github.com/tarunm/pubsub-system/internal/tracing"
+)
+
+// Trace context of a publisher's span, as a client would send it
+const (
+	publisherTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	publisherSpanID      = "00f067aa0ba902b7"
+	publisherTraceparent = "00-" + publisherTraceID + "-" + publisherSpanID + "-01"
+)
+
+// TestTracing_PropagatesThroughMessages tests that publish, fan-out, enqueue and write
+// spans continue the publisher's trace and the consumer receives the write span's context
+func TestTracing_PropagatesThroughMessages(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "spans.json")
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.TracingExporter = tracing.ExporterFile
+		cfg.TracingFile = traceFile
+		cfg.TracingServiceName = "pubsub-test"
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	subscriber := ConnectWebSocket(t, server.WSURL, "traced-subscriber")
+	defer subscriber.Close()
+	Subscribe(t, subscriber, "orders", 0, "sub-1")
+	WaitForAck(t, subscriber, "sub-1", 2*time.Second)
+
+	publisher := ConnectWebSocket(t, server.WSURL, "traced-publisher")
+	defer publisher.Close()
+	publishWithHeaders(t, publisher, "orders", map[string]string{
+		"traceparent": publisherTraceparent,
+		"tenant-hint": "kept",
+	}, "pub-1")
+	WaitForAck(t, publisher, "pub-1", 2*time.Second)
+
+	event := WaitForEvent(t, subscriber, 2*time.Second)
+	headers := event.Message.Headers
+	if headers["tenant-hint"] != "kept" {
+		t.Errorf("Expected application headers to be delivered, got %v", headers)
+	}
+	consumerTraceID, consumerParentID := parseTraceparent(t, headers["traceparent"])
+	if consumerTraceID != publisherTraceID {
+		t.Fatalf("Expected consumer to continue trace %s, got %s", publisherTraceID, consumerTraceID)
+	}
+
+	// Each stage is the parent of the next, ending with the span the consumer continues from
+	spans := waitForSpans(t, traceFile, 4)
+	parentID := publisherSpanID
+	for _, name := range []string{"publish orders", "fanout orders", "enqueue orders", "send orders"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("Expected span %q, got %v", name, spanNames(spans))
+		}
+		if span.SpanContext.TraceID != publisherTraceID {
+			t.Errorf("Expected span %q in trace %s, got %s", name, publisherTraceID, span.SpanContext.TraceID)
+		}
+		if span.Parent.SpanID != parentID {
+			t.Errorf("Expected span %q to have parent %s, got %s", name, parentID, span.Parent.SpanID)
+		}
+		parentID = span.SpanContext.SpanID
+	}
+	if consumerParentID != parentID {
+		t.Errorf("Expected consumer traceparent to reference the send span %s, got %s", parentID, consumerParentID)
+	}
+	if spans["enqueue orders"].attribute("messaging.client.id") != "traced-subscriber" {
+		t.Errorf("Expected enqueue span to name the subscriber, got %+v", spans["enqueue orders"].Attributes)
+	}
+}
+
+// TestTracing_StartsTraceWithoutTraceparent tests that messages without a trace context start a new trace
+func TestTracing_StartsTraceWithoutTraceparent(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "spans.json")
+	server, cleanup := SetupTestServerWithConfig(t, nil, func(cfg *config.Config) {
+		cfg.TracingExporter = tracing.ExporterFile
+		cfg.TracingFile = traceFile
+	})
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "untraced-client")
+	defer conn.Close()
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+	Publish(t, conn, "orders", uuid.New().String(), "hello", "pub-1")
+
+	event := WaitForEvent(t, conn, 2*time.Second)
+	traceID, _ := parseTraceparent(t, event.Message.Headers["traceparent"])
+
+	spans := waitForSpans(t, traceFile, 4)
+	publish := spans["publish orders"]
+	if publish.Parent.SpanID != "0000000000000000" {
+		t.Errorf("Expected publish span to start a trace, got parent %s", publish.Parent.SpanID)
+	}
+	if publish.SpanContext.TraceID != traceID {
+		t.Errorf("Expected consumer to receive trace %s, got %s", publish.SpanContext.TraceID, traceID)
+	}
+}
+
+// TestTracing_Disabled tests that headers pass through unchanged when tracing is disabled
+func TestTracing_Disabled(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "plain-client")
+	defer conn.Close()
+	Subscribe(t, conn, "orders", 0, "sub-1")
+	WaitForAck(t, conn, "sub-1", 2*time.Second)
+
+	publishWithHeaders(t, conn, "orders", map[string]string{"traceparent": publisherTraceparent}, "pub-1")
+	if event := WaitForEvent(t, conn, 2*time.Second); event.Message.Headers["traceparent"] != publisherTraceparent {
+		t.Errorf("Expected traceparent to be delivered unchanged, got %v", event.Message.Headers)
+	}
+
+	Publish(t, conn, "orders", uuid.New().String(), "no headers", "pub-2")
+	if event := WaitForEvent(t, conn, 2*time.Second); event.Message.Headers != nil {
+		t.Errorf("Expected no headers, got %v", event.Message.Headers)
+	}
+}
+
+// TestTracing_InvalidHeaders tests that oversized header sets are rejected
+func TestTracing_InvalidHeaders(t *testing.T) {
+	server, cleanup := SetupTestServer(t)
+	defer cleanup()
+
+	CreateTopic(t, server.URL, "orders").Body.Close()
+
+	conn := ConnectWebSocket(t, server.WSURL, "header-client")
+	defer conn.Close()
+
+	headers := make(map[string]string)
+	for i := 0; i < 33; i++ {
+		headers[fmt.Sprintf("h%d", i)] = "v"
+	}
+	publishWithHeaders(t, conn, "orders", headers, "pub-1")
+	msg := ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "BAD_REQUEST" {
+		t.Fatalf("Expected BAD_REQUEST error, got %+v", msg)
+	}
+
+	publishWithHeaders(t, conn, "orders", map[string]string{"big": strings.Repeat("x", 1024)}, "pub-2")
+	msg = ReceiveMessage(t, conn, 2*time.Second)
+	if msg.Type != "error" || msg.Error == nil || msg.Error.Code != "BAD_REQUEST" {
+		t.Fatalf("Expected BAD_REQUEST error, got %+v", msg)
+	}
+}
+
+// Helper functions for tracing tests
+
+// exportedSpan is the subset of a span written by the file exporter used by the tests
+type exportedSpan struct {
+	Name        string
+	SpanContext struct {
+		TraceID string
+		SpanID  string
+	}
+	Parent struct {
+		SpanID string
+	}
+	Attributes []struct {
+		Key   string
+		Value struct {
+			Value interface{}
+		}
+	}
+}
+
+func (s exportedSpan) attribute(key string) interface{} {
+	for _, attr := range s.Attributes {
+		if attr.Key == key {
+			return attr.Value.Value
+		}
+	}
+	return nil
+}
+
+func publishWithHeaders(t *testing.T, conn *websocket.Conn, topic string, headers map[string]string, requestID string) {
+	t.Helper()
+
+	SendMessage(t, conn, models.ClientMessage{
+		Type:  "publish",
+		Topic: topic,
+		Message: &models.Message{
+			ID:      uuid.New().String(),
+			Payload: "traced",
+			Headers: headers,
+		},
+		RequestID: requestID,
+	})
+}
+
+// parseTraceparent returns the trace ID and parent span ID of a W3C traceparent header
+func parseTraceparent(t *testing.T, traceparent string) (string, string) {
+	t.Helper()
+
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		t.Fatalf("Invalid traceparent %q", traceparent)
+	}
+	return parts[1], parts[2]
+}
+
+// waitForSpans waits until the trace file holds at least count spans and returns them by name
+// The write span ends after the event is sent, so it can trail the consumer
+func waitForSpans(t *testing.T, path string, count int) map[string]exportedSpan {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		spans := readSpans(t, path)
+		if len(spans) >= count || time.Now().After(deadline) {
+			return spans
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func readSpans(t *testing.T, path string) map[string]exportedSpan {
+	t.Helper()
+
+	spans := make(map[string]exportedSpan)
+	file, err := os.Open(path)
+	if err != nil {
+		return spans
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var span exportedSpan
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("Invalid span line %q: %v", scanner.Text(), err)
+		}
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func spanNames(spans map[string]exportedSpan) []string {
+	names := make([]string, 0, len(spans))
+	for name := range spans {
+		names = append(names, name)
+	}
+	return names
+}